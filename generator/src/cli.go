@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/violetexistence/traveller/generator/sector"
+	"github.com/violetexistence/traveller/generator/sectorfile"
+	"github.com/violetexistence/traveller/generator/ship"
+)
+
+// commands run without a terminal user interface, for scripting batches.
+var commands = map[string]func(args []string) error{
+	"sector": runSector,
+	"world":  runWorld,
+	"ship":   runShip,
+	"lint":   runLint,
+	"stats":  runStats,
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: generator [flags]             interactive menu\n")
+	fmt.Fprintf(out, "       generator sector [flags]      write a sector, generated or read with -in\n")
+	fmt.Fprintf(out, "       generator world [flags]       write a single world\n")
+	fmt.Fprintf(out, "       generator ship [flags]        write a typical ship for a role\n")
+	fmt.Fprintf(out, "       generator lint [flags] file   check a sector file against its UWPs\n")
+	fmt.Fprintf(out, "       generator stats [flags]       compare many sectors against the rules' tables\n\n")
+	flag.PrintDefaults()
+}
+
+// commonFlags registers the flags shared by every subcommand.
+func commonFlags(fs *flag.FlagSet, opts *sector.Options, format *string, out *string) {
+	fs.Int64Var(&opts.Seed, "seed", 0, "seed for reproducible generation (0 picks one at random)")
+	fs.StringVar(&opts.Name, "name", "", "name instead of a rolled one")
+	fs.BoolVar(&opts.Online, "online", false, "fetch planet names from donjon.bin.sh")
+	fs.StringVar(format, "format", string(sectorfile.Tab), "output format: t5tab or column")
+	fs.StringVar(out, "out", "", "file to write instead of stdout")
+	fs.Func("rules", "world creation rules: classic, mgt2 or t5 (default t5)", func(name string) (err error) {
+		opts.Rules, err = sector.ParseRuleSet(name)
+		return err
+	})
+}
+
+func runSector(args []string) error {
+	var opts sector.Options
+	var format, out, meta, systems, svg, subsector string
+
+	fs := flag.NewFlagSet("sector", flag.ContinueOnError)
+	commonFlags(fs, &opts, &format, &out)
+	fs.StringVar(&opts.Path, "in", "", "sector file to read instead of generating one")
+	fs.StringVar(&meta, "meta", "", "file to write the sector metadata XML to")
+	fs.StringVar(&systems, "systems", "", "file to write a listing of every star system to")
+	fs.StringVar(&svg, "svg", "", "file to draw an SVG map of the sector to")
+	fs.StringVar(&subsector, "subsector", "", "draw only this subsector, A to P, on the map")
+	fs.Var(&opts.Density, "density", "rift, sparse, scattered, standard, dense or cluster, with\noverrides by subsector such as sparse,F=cluster")
+	fs.Func("sophonts", "tab delimited file of the sophonts to settle instead of the Gateway ones", func(path string) error {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		opts.Sophonts, err = sector.ReadSophonts(file)
+		return err
+	})
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	f, err := sectorfile.ParseFormat(format)
+	if err != nil {
+		return err
+	}
+
+	return withOutput(out, func(w io.Writer) error {
+		return withOptionalOutput(meta, func(m io.Writer) error {
+			return withOptionalOutput(systems, func(sys io.Writer) error {
+				return withOptionalOutput(svg, func(picture io.Writer) error {
+					return sector.Generate(sector.Output{Data: w, Metadata: m, Systems: sys, Map: picture, MapSubsector: subsector, Status: os.Stderr}, opts, f)
+				})
+			})
+		})
+	})
+}
+
+func runWorld(args []string) error {
+	var opts sector.Options
+	var format, out, hex string
+
+	fs := flag.NewFlagSet("world", flag.ContinueOnError)
+	commonFlags(fs, &opts, &format, &out)
+	fs.StringVar(&hex, "hex", "0101", "hex location of the world")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	f, err := sectorfile.ParseFormat(format)
+	if err != nil {
+		return err
+	}
+
+	return withOutput(out, func(w io.Writer) error {
+		return sector.GenerateWorld(w, os.Stderr, opts, hex, f)
+	})
+}
+
+func runShip(args []string) error {
+	var opts ship.Options
+	var role, format, out string
+
+	fs := flag.NewFlagSet("ship", flag.ContinueOnError)
+	fs.Int64Var(&opts.Seed, "seed", 0, "seed for reproducible generation (0 picks one at random)")
+	fs.StringVar(&role, "role", "", "free-trader, scout or patrol-corvette (empty rolls one)")
+	fs.StringVar(&format, "format", string(ship.Text), "output format: text or json")
+	fs.StringVar(&out, "out", "", "file to write instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if role != "" {
+		r, err := ship.ParseRole(role)
+		if err != nil {
+			return err
+		}
+		opts.Role = r
+	}
+
+	f, err := ship.ParseFormat(format)
+	if err != nil {
+		return err
+	}
+
+	return withOutput(out, func(w io.Writer) error {
+		return ship.Generate(w, opts, f)
+	})
+}
+
+func runLint(args []string) error {
+	var fix bool
+	var out string
+
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	fs.BoolVar(&fix, "fix", false, "rewrite the file with every problem fixed")
+	fs.StringVar(&out, "out", "", "file to write the fixed sector to instead of the input (implies -fix)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("lint: expected one sector file, got %d", fs.NArg())
+	}
+	path := fs.Arg(0)
+
+	if out == "" && !fix {
+		problems, err := sector.Lint(os.Stdout, nil, path)
+		if err != nil {
+			return err
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("%s: %d problems", path, len(problems))
+		}
+		return nil
+	}
+
+	if out == "" {
+		out = path
+	}
+
+	// The fixed sector is held until the file has been read, since it may
+	// be going back over it.
+	var fixed bytes.Buffer
+	problems, err := sector.Lint(os.Stdout, &fixed, path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("fixed %d problems in %s\n", len(problems), out)
+
+	return os.WriteFile(out, fixed.Bytes(), 0o644)
+}
+
+func runStats(args []string) error {
+	var opts sector.Options
+	var sectors int
+	var tolerance float64
+	var out string
+
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	fs.IntVar(&sectors, "n", 10, "number of sectors to generate")
+	fs.Int64Var(&opts.Seed, "seed", 0, "seed of the first sector, counting up from there (0 picks one at random)")
+	fs.Float64Var(&tolerance, "tolerance", sector.DefaultTolerance, "share of worlds a distribution may stray from its expected odds before it is flagged")
+	fs.StringVar(&out, "out", "", "file to write the report to instead of stdout")
+	fs.Func("rules", "world creation rules: classic, mgt2 or t5 (default t5)", func(name string) (err error) {
+		opts.Rules, err = sector.ParseRuleSet(name)
+		return err
+	})
+	fs.Var(&opts.Density, "density", "rift, sparse, scattered, standard, dense or cluster, with\noverrides by subsector such as sparse,F=cluster")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// The report is worth keeping even when it finds drift.
+	var drift error
+	if err := withOutput(out, func(w io.Writer) error {
+		drift = sector.Stats(w, opts, sectors, tolerance)
+		return nil
+	}); err != nil {
+		return err
+	}
+	return drift
+}
+
+// withOutput hands write the named file, or stdout when no name is given.
+// The file is only written once write has finished without an error, so it
+// can be the very file that write reads from.
+func withOutput(name string, write func(w io.Writer) error) error {
+	if name == "" {
+		return write(os.Stdout)
+	}
+
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		return err
+	}
+	return os.WriteFile(name, buf.Bytes(), 0o644)
+}
+
+// withOptionalOutput hands write the named file, or nil when no name is given.
+func withOptionalOutput(name string, write func(w io.Writer) error) error {
+	if name == "" {
+		return write(nil)
+	}
+	return withOutput(name, write)
+}
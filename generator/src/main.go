@@ -1,8 +1,10 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"github.com/violetexistence/traveller/generator/sector"
+	"github.com/violetexistence/traveller/generator/ship"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -13,6 +15,8 @@ type sessionState int
 const (
 	mainMenu sessionState = iota
 	sectorGenerator
+	worldGenerator
+	shipGenerator
 )
 
 type choice struct {
@@ -25,10 +29,12 @@ type model struct {
 	cursor    int
 	state     sessionState
 	generator tea.Model
+	opts      sector.Options
 }
 
-func initialModel() model {
+func initialModel(opts sector.Options) model {
 	return model{
+		opts: opts,
 		choices: []choice{
 			{shortcut: "s", label: "Sector"},
 			{shortcut: "w", label: "World"},
@@ -38,6 +44,18 @@ func initialModel() model {
 	}
 }
 
+// busyGenerator is a generator that can be part way through something of
+// its own, such as a prompt, and needs Esc and q until it is done.
+type busyGenerator interface {
+	Busy() bool
+}
+
+// busy is true while the generator on show needs Esc and q for itself.
+func (m model) busy() bool {
+	g, ok := m.generator.(busyGenerator)
+	return ok && g.Busy()
+}
+
 func (m model) Init() tea.Cmd {
 	return nil
 }
@@ -48,7 +66,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
-		case "ctrl+c", "q", "esc":
+		case "q", "esc":
+			if m.busy() {
+				break
+			}
+			fallthrough
+		case "ctrl+c":
 			if m.state == mainMenu {
 				return m, tea.Quit
 			} else {
@@ -75,7 +98,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			case "s":
 				m.state = sectorGenerator
-				m.generator = sector.New()
+				m.generator = sector.New(m.opts)
+				cmds = append(cmds, m.generator.Init())
+			case "w":
+				m.state = worldGenerator
+				m.generator = sector.NewWorld(m.opts)
+				cmds = append(cmds, m.generator.Init())
+			case "p":
+				m.state = shipGenerator
+				m.generator = ship.New(ship.Options{Seed: m.opts.Seed})
 				cmds = append(cmds, m.generator.Init())
 			case "enter", " ":
 				choice := m.choices[m.cursor]
@@ -85,7 +116,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				})
 			}
 		}
-	case sectorGenerator:
+	default:
 		generator, cmd := m.generator.Update(msg)
 		m.generator = generator
 		cmds = append(cmds, cmd)
@@ -96,9 +127,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m model) View() string {
 	switch m.state {
-	case sectorGenerator:
-		return m.generator.View()
-	default:
+	case mainMenu:
 		s := "What should we generate?\n\n"
 
 		for i, choice := range m.choices {
@@ -113,11 +142,38 @@ func (m model) View() string {
 		s += "\nPress q to quit.\n"
 
 		return s
+	default:
+		return m.generator.View()
 	}
 }
 
 func main() {
-	p := tea.NewProgram(initialModel())
+	if len(os.Args) > 1 {
+		if run, ok := commands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				if err != flag.ErrHelp {
+					fmt.Fprintln(os.Stderr, err)
+				}
+				os.Exit(2)
+			}
+			return
+		}
+	}
+
+	flag.Usage = usage
+
+	var opts sector.Options
+	flag.Int64Var(&opts.Seed, "seed", 0, "seed for reproducible generation (0 picks one at random)")
+	flag.BoolVar(&opts.Online, "online", false, "fetch planet names from donjon.bin.sh")
+	flag.StringVar(&opts.Path, "open", "", "sector file to open in the sector viewer")
+	flag.Var(&opts.Density, "density", "world density for generated sectors, such as sparse,F=cluster")
+	flag.Func("rules", "world creation rules: classic, mgt2 or t5 (default t5)", func(name string) (err error) {
+		opts.Rules, err = sector.ParseRuleSet(name)
+		return err
+	})
+	flag.Parse()
+
+	p := tea.NewProgram(initialModel(opts))
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 		os.Exit(1)
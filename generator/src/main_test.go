@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/violetexistence/traveller/generator/sector"
+)
+
+// run feeds what a command produces back into the model, leaving out the
+// spinner's ticks.
+func run(m tea.Model, cmd tea.Cmd) tea.Model {
+	if cmd == nil {
+		return m
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, c := range msg {
+			m = run(m, c)
+		}
+	case spinner.TickMsg:
+	default:
+		m, _ = m.Update(msg)
+	}
+	return m
+}
+
+func press(m tea.Model, keys ...tea.KeyMsg) tea.Model {
+	for _, k := range keys {
+		m, _ = m.Update(k)
+	}
+	return m
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+var (
+	enter = tea.KeyMsg{Type: tea.KeyEnter}
+	esc   = tea.KeyMsg{Type: tea.KeyEsc}
+)
+
+func TestGeneratorKeepsItsKeys(t *testing.T) {
+	var m tea.Model = initialModel(sector.Options{Seed: 2112})
+	m, cmd := m.Update(runes("s"))
+	m = run(m, cmd)
+
+	m = press(m, runes("e"), runes("q"))
+	if m.(model).state != sectorGenerator || !m.(model).busy() {
+		t.Fatal("expected q to be typed into the prompt")
+	}
+	m = press(m, esc)
+	if m.(model).state != sectorGenerator || m.(model).busy() {
+		t.Fatal("expected escape to cancel the prompt")
+	}
+
+	m = press(m, enter)
+	if !m.(model).busy() {
+		t.Fatal("expected the detail panel open")
+	}
+	m = press(m, esc)
+	if m.(model).state != sectorGenerator || m.(model).busy() {
+		t.Fatal("expected escape to close the detail panel")
+	}
+
+	m = press(m, esc)
+	if m.(model).state != mainMenu {
+		t.Fatal("expected escape to go back to the menu")
+	}
+}
+
+func TestSectorInPlace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sector.tab")
+	if err := runSector([]string{"-seed", "1105", "-out", path}); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(path)
+
+	if err := runSector([]string{"-in", path, "-out", path}); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := os.ReadFile(path)
+	if len(after) == 0 || !bytes.Equal(before, after) {
+		t.Fatalf("expected the sector written back unchanged, got %d bytes from %d", len(after), len(before))
+	}
+}
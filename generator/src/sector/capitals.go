@@ -0,0 +1,89 @@
+package sector
+
+import (
+	"slices"
+	"strings"
+)
+
+// chooseCapitals seats a government in each subsector and one over the
+// whole sector. The subsector capital, Cp, is the most important world with
+// a class A or B starport, and the most important of those becomes the
+// sector capital, Cs, instead. A subsector without such a port has no
+// capital. Importance and nobility are worked out again afterwards, since
+// both follow from the capital codes. Only T5 marks capitals in the remarks.
+func (r *roller) chooseCapitals(s sector) sector {
+	if !r.rules.capitals {
+		return s
+	}
+
+	s.hexes = slices.Clone(s.hexes)
+	for i := range s.hexes {
+		s.hexes[i].remarks = withoutRemarks(s.hexes[i].remarks, subsectorCapitol, sectorCapitol)
+	}
+
+	var capitals []int
+	for sub := 0; sub < 16; sub++ {
+		best := -1
+		for i, hex := range s.hexes {
+			x, y, ok := parseLocationCode(hex.location)
+			if !ok || subsectorOf(x, y) != sub || !is(St, "AB")(hex) {
+				continue
+			}
+			if best < 0 || outranks(hex, s.hexes[best]) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			capitals = append(capitals, best)
+		}
+	}
+
+	seat := -1
+	for _, i := range capitals {
+		if seat < 0 || outranks(s.hexes[i], s.hexes[seat]) {
+			seat = i
+		}
+	}
+
+	for _, i := range capitals {
+		code := subsectorCapitol
+		if i == seat {
+			code = sectorCapitol
+		}
+		// The capital codes go ahead of the sophont remarks, so that
+		// choosing again leaves the remarks as they are.
+		remarks := strings.Fields(s.hexes[i].remarks)
+		at := slices.IndexFunc(remarks, sophontRemark)
+		if at < 0 {
+			at = len(remarks)
+		}
+		s.hexes[i].remarks = strings.Join(slices.Insert(remarks, at, string(code)), " ")
+	}
+
+	for i, hex := range s.hexes {
+		hex.importance = getImportanceExtension(hex)
+		hex.nobility = getNobility(hex)
+		s.hexes[i] = hex
+	}
+
+	return s
+}
+
+// outranks is whether world a makes a better capital than world b: the more
+// important, then the more populous, then the more advanced.
+func outranks(a hexInfo, b hexInfo) bool {
+	if a.importance != b.importance {
+		return a.importance > b.importance
+	}
+	if pa, pb := getNumericUwpValue(a.uwp, Pop), getNumericUwpValue(b.uwp, Pop); pa != pb {
+		return pa > pb
+	}
+	return getNumericUwpValue(a.uwp, TL) > getNumericUwpValue(b.uwp, TL)
+}
+
+// withoutRemarks drops the given codes from a remarks column.
+func withoutRemarks(remarks string, codes ...tradeCode) string {
+	return strings.Join(slices.DeleteFunc(strings.Fields(remarks), func(remark string) bool {
+		return slices.Contains(codes, tradeCode(remark))
+	}), " ")
+}
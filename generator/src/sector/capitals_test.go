@@ -0,0 +1,75 @@
+package sector
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestChooseCapitals(t *testing.T) {
+	s := buildSector(Options{Seed: 2112})
+
+	var seat hexInfo
+	seats := 0
+	for sub := 0; sub < 16; sub++ {
+		var capitals []hexInfo
+		ports := false
+		for _, hex := range s.subsector(sub) {
+			ports = ports || is(St, "AB")(hex)
+			if hasTradeCode(subsectorCapitol)(hex) || hasTradeCode(sectorCapitol)(hex) {
+				capitals = append(capitals, hex)
+			}
+		}
+
+		if !ports {
+			if len(capitals) > 0 {
+				t.Errorf("subsector %c has no A or B port but a capital", 'A'+sub)
+			}
+			continue
+		}
+		if len(capitals) != 1 {
+			t.Fatalf("subsector %c has %d capitals", 'A'+sub, len(capitals))
+		}
+
+		capital := capitals[0]
+		for _, hex := range s.subsector(sub) {
+			if is(St, "AB")(hex) && outranks(hex, capital) {
+				t.Errorf("%s %s outranks the capital of %c, %s", hex.location, hex.name, 'A'+sub, capital.name)
+			}
+		}
+		if !slices.Contains(capital.nobility, grandDuke) || slices.Contains(capital.nobility, duke) {
+			t.Errorf("expected %s to be ruled by a grand duke, got %q", capital.name, formatNobility(capital.nobility))
+		}
+
+		if hasTradeCode(sectorCapitol)(capital) {
+			seat = capital
+			seats++
+		}
+		if archdukes := slices.Contains(capital.nobility, archduke); archdukes != hasTradeCode(sectorCapitol)(capital) {
+			t.Errorf("expected an archduke at %s only if it is the sector capital, got %q", capital.name, formatNobility(capital.nobility))
+		}
+	}
+
+	if seats != 1 {
+		t.Fatalf("expected one sector capital, got %d", seats)
+	}
+	for _, hex := range s.hexes {
+		if hasTradeCode(subsectorCapitol)(hex) && outranks(hex, seat) {
+			t.Errorf("%s outranks the sector capital %s", hex.name, seat.name)
+		}
+	}
+}
+
+func TestChooseCapitalsAgain(t *testing.T) {
+	s := buildSector(Options{Seed: 2112})
+	again := s.roller("").chooseCapitals(s)
+
+	for i, hex := range again.hexes {
+		if hex.remarks != s.hexes[i].remarks {
+			t.Fatalf("choosing again changed %s from %q to %q", hex.location, s.hexes[i].remarks, hex.remarks)
+		}
+		if strings.Count(hex.remarks, "Cp")+strings.Count(hex.remarks, "Cs") > 1 {
+			t.Fatalf("%s has more than one capital code: %q", hex.location, hex.remarks)
+		}
+	}
+}
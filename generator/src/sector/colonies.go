@@ -0,0 +1,114 @@
+package sector
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	// colonyReach is how far an owner holds colonies from home.
+	colonyReach = 2
+	// outpostOdds is one in how many small settlements within reach of an
+	// owner belong to it, besides the worlds that are colonies by their UWP.
+	outpostOdds = 2
+)
+
+// colonist is whether a world is populous and advanced enough to own
+// colonies: high population and a jump drive of its own.
+func colonist(hex hexInfo) bool {
+	return is(Pop, "89ABCDEF")(hex) && getNumericUwpValue(hex.uwp, TL) >= 9
+}
+
+// claimColonies gives each colony, and some of the other small settlements,
+// to the nearest world within reach that can own them. An owned world is
+// remarked O: and its owner's hex, as in O:1108, and owes allegiance where
+// its owner does: a colony of a polity world joins the polity, while one
+// whose owner is independent or a client state follows it there. A world
+// some polity already holds can only be owned from inside that polity.
+// Only T5 marks owners in the remarks.
+func (r *roller) claimColonies(s sector) sector {
+	if !r.rules.owners {
+		return s
+	}
+
+	s.hexes = slices.Clone(s.hexes)
+
+	var owners []int
+	for i, hex := range s.hexes {
+		if colonist(hex) {
+			owners = append(owners, i)
+		}
+	}
+
+	for i, hex := range s.hexes {
+		if colonist(hex) || hasOwner(hex) || includeTradeCodes(capitol, subsectorCapitol, sectorCapitol)(hex) {
+			continue
+		}
+		switch {
+		case hasTradeCode(colony)(hex):
+		case is(Pop, "123")(hex) && r.rng.Intn(outpostOdds) == 0:
+		default:
+			continue
+		}
+
+		owner := -1
+		for _, o := range owners {
+			other := s.hexes[o]
+			d := hexDistance(hex.location, other.location)
+			if d > colonyReach || !sharesAllegiance(hex, other) {
+				continue
+			}
+			if owner < 0 {
+				owner = o
+				continue
+			}
+			nearest := hexDistance(hex.location, s.hexes[owner].location)
+			if d < nearest || d == nearest && outranks(other, s.hexes[owner]) {
+				owner = o
+			}
+		}
+		if owner < 0 {
+			continue
+		}
+
+		// The owner goes ahead of the sophont remarks, as Traveller Map
+		// lists them.
+		remarks := strings.Fields(hex.remarks)
+		at := slices.IndexFunc(remarks, sophontRemark)
+		if at < 0 {
+			at = len(remarks)
+		}
+		hex.remarks = strings.Join(slices.Insert(remarks, at, "O:"+s.hexes[owner].location), " ")
+		hex.allegiance = s.hexes[owner].allegiance
+		s.hexes[i] = r.rederive(hex)
+	}
+
+	var codes []string
+	for _, border := range s.borders {
+		if !slices.Contains(codes, border.Allegiance) {
+			codes = append(codes, border.Allegiance)
+		}
+	}
+	s.borders = polityBorders(s, codes)
+
+	return s
+}
+
+// sharesAllegiance is whether a world could be owned from another without
+// leaving the polity that holds it. Independent worlds and client states
+// are held by no polity.
+func sharesAllegiance(hex hexInfo, owner hexInfo) bool {
+	return hex.allegiance == owner.allegiance || hex.allegiance == independent ||
+		strings.HasPrefix(hex.allegiance, "Cs") || strings.HasPrefix(hex.allegiance, "Na")
+}
+
+// owner is the world that owns a colony, if its remarks name one in this
+// sector.
+func (s sector) owner(hex hexInfo) (hexInfo, bool) {
+	for _, remark := range strings.Fields(hex.remarks) {
+		if location, ok := strings.CutPrefix(remark, "O:"); ok {
+			return s.worldAt(location)
+		}
+	}
+	return hexInfo{}, false
+}
@@ -0,0 +1,76 @@
+package sector
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClaimColonies(t *testing.T) {
+	s := buildSector(Options{Seed: 2112})
+
+	owned := 0
+	for _, hex := range s.hexes {
+		owner, ok := s.owner(hex)
+		if !ok {
+			if hasOwner(hex) {
+				t.Errorf("%s %s names an owner not in the sector: %q", hex.location, hex.name, hex.remarks)
+			}
+			continue
+		}
+		owned++
+
+		if !colonist(owner) {
+			t.Errorf("%s %s cannot own colonies", owner.location, owner.name)
+		}
+		if d := hexDistance(hex.location, owner.location); d > colonyReach {
+			t.Errorf("%s %s is %d parsecs from its owner", hex.location, hex.name, d)
+		}
+		if hex.allegiance != owner.allegiance {
+			t.Errorf("%s %s owes %s but its owner owes %s", hex.location, hex.name, hex.allegiance, owner.allegiance)
+		}
+		if hasTradeCode(militaryRule)(hex) {
+			t.Errorf("%s %s is under military rule as well as owned", hex.location, hex.name)
+		}
+		if _, problems := s.roller("").lintWorld(hex, true); len(problems) > 0 {
+			t.Errorf("%s %s: %v", hex.location, hex.name, problems)
+		}
+	}
+	if owned == 0 {
+		t.Fatal("expected some owned worlds")
+	}
+
+	lines := 0
+	for sub := 0; sub < 16; sub++ {
+		for _, line := range s.coloniesIn(sub) {
+			if !strings.Contains(line, " owned by ") {
+				t.Errorf("unexpected colony line %q", line)
+			}
+			lines++
+		}
+	}
+	if lines != owned {
+		t.Fatalf("expected %d colony lines, got %d", owned, lines)
+	}
+}
+
+func TestClaimColoniesKeepsPolities(t *testing.T) {
+	s := sector{
+		hexes: []hexInfo{
+			{location: "0101", name: "Home", uwp: "A8869A9-C", allegiance: "HoFd"},
+			{location: "0102", name: "Outpost", uwp: "C544263-8", remarks: "Lo Cy Mr", allegiance: "NaHu"},
+			{location: "0201", name: "Rival", uwp: "C544263-8", remarks: "Lo Cy Mr", allegiance: "RiUn"},
+		},
+	}
+	s = newRoller(1, T5).claimColonies(s)
+
+	if s.hexes[2].allegiance != "RiUn" || hasOwner(s.hexes[2]) {
+		t.Errorf("a world of another polity was claimed: %+v", s.hexes[2])
+	}
+	outpost := s.hexes[1]
+	if owner, ok := s.owner(outpost); !ok || owner.name != "Home" || outpost.allegiance != "HoFd" {
+		t.Errorf("expected Home to own the outpost, got %q %s", outpost.remarks, outpost.allegiance)
+	}
+	if outpost.remarks != "Lo Cy O:0101" {
+		t.Errorf("expected military rule to give way to the owner, got %q", outpost.remarks)
+	}
+}
@@ -0,0 +1,205 @@
+package sector
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+// densityPresets are the share of hexes holding a world, from the T5 system
+// presence table.
+var densityPresets = []struct {
+	name   string
+	chance float64
+}{
+	{"rift", 0.03},
+	{"sparse", 0.17},
+	{"scattered", 0.33},
+	{"standard", 0.50},
+	{"dense", 0.66},
+	{"cluster", 0.83},
+}
+
+// defaultDensity is close to the flat 7 in 20 the generator always used.
+const defaultDensity = "scattered"
+
+// Density is the preset for each subsector, A to P. Subsectors left empty
+// use the default. It reads and writes specs like "sparse,F=cluster,K=rift":
+// a bare preset covers the whole sector and a lettered one a single
+// subsector.
+type Density [16]string
+
+func (d Density) String() string {
+	counts := map[string]int{}
+	for _, preset := range d {
+		if preset == "" {
+			preset = defaultDensity
+		}
+		counts[preset]++
+	}
+
+	// The most common preset covers the sector, the rest are listed.
+	base := defaultDensity
+	for _, p := range densityPresets {
+		if counts[p.name] > counts[base] {
+			base = p.name
+		}
+	}
+
+	spec := []string{base}
+	for i, preset := range d {
+		if preset == "" {
+			preset = defaultDensity
+		}
+		if preset != base {
+			spec = append(spec, fmt.Sprintf("%c=%s", 'A'+i, preset))
+		}
+	}
+
+	return strings.Join(spec, ",")
+}
+
+// Set parses a density spec, so that Density can be used as a flag.
+func (d *Density) Set(spec string) error {
+	var parsed Density
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+
+		subsector, preset, lettered := strings.Cut(part, "=")
+		if !lettered {
+			preset = subsector
+		}
+		if _, err := densityChance(preset); err != nil {
+			return err
+		}
+
+		if !lettered {
+			for i := range parsed {
+				parsed[i] = preset
+			}
+			continue
+		}
+
+		if len(subsector) != 1 || subsector[0] < 'a' || subsector[0] > 'p' {
+			return fmt.Errorf("unknown subsector %q: expected A to P", subsector)
+		}
+		parsed[subsector[0]-'a'] = preset
+	}
+
+	*d = parsed
+	return nil
+}
+
+// ParseDensity reads a density spec such as "sparse,F=cluster".
+func ParseDensity(spec string) (Density, error) {
+	var d Density
+	err := d.Set(spec)
+	return d, err
+}
+
+func (d Density) isDefault() bool {
+	for _, preset := range d {
+		if preset != "" && preset != defaultDensity {
+			return false
+		}
+	}
+	return true
+}
+
+func densityChance(preset string) (float64, error) {
+	if preset == "" {
+		preset = defaultDensity
+	}
+	for _, p := range densityPresets {
+		if p.name == preset {
+			return p.chance, nil
+		}
+	}
+
+	var names []string
+	for _, p := range densityPresets {
+		names = append(names, p.name)
+	}
+	return 0, fmt.Errorf("unknown density %q: expected one of %s", preset, strings.Join(names, ", "))
+}
+
+// subsectorOf is the index, 0 to 15, of the subsector holding a hex.
+func subsectorOf(hx, hy int) int {
+	return (hy-1)/10*4 + (hx-1)/8
+}
+
+// densityNoise is smooth value noise over the sector grid, from 0 to 1. A
+// coarse layer lays down voids and clusters several parsecs across, and a
+// finer one breaks up their edges.
+type densityNoise struct {
+	layers []noiseLayer
+}
+
+type noiseLayer struct {
+	cell    int
+	weight  float64
+	lattice [][]float64
+}
+
+func newDensityNoise(rng *rand.Rand) densityNoise {
+	var n densityNoise
+	for _, l := range []struct {
+		cell   int
+		weight float64
+	}{{8, 0.7}, {3, 0.3}} {
+		layer := noiseLayer{cell: l.cell, weight: l.weight}
+		for x := 0; x <= 32/l.cell+1; x++ {
+			var column []float64
+			for y := 0; y <= 40/l.cell+1; y++ {
+				column = append(column, rng.Float64())
+			}
+			layer.lattice = append(layer.lattice, column)
+		}
+		n.layers = append(n.layers, layer)
+	}
+	return n
+}
+
+func (n densityNoise) at(hx, hy int) float64 {
+	var value float64
+	for _, l := range n.layers {
+		fx, fy := float64(hx-1)/float64(l.cell), float64(hy-1)/float64(l.cell)
+		x, y := int(fx), int(fy)
+		tx, ty := smoothstep(fx-float64(x)), smoothstep(fy-float64(y))
+
+		top := lerp(l.lattice[x][y], l.lattice[x+1][y], tx)
+		bottom := lerp(l.lattice[x][y+1], l.lattice[x+1][y+1], tx)
+		value += l.weight * lerp(top, bottom, ty)
+	}
+	return value
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+func smoothstep(t float64) float64 {
+	return t * t * (3 - 2*t)
+}
+
+// placementChance bends the preset by the noise. Where the noise bottoms out
+// the hex is a void, and where it peaks the chance is doubled, or certain for
+// the denser presets. Across a subsector it averages out to the preset.
+func placementChance(density float64, noise float64) float64 {
+	// Value noise bunches up around the middle; spread it back out so the
+	// voids are empty and the clusters full.
+	noise = applyUnit(0.5 + (noise-0.5)*2.2)
+
+	if density <= 0.5 {
+		return density * 2 * noise
+	}
+	return 1 - (1-density)*2*(1-noise)
+}
+
+func applyUnit(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
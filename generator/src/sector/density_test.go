@@ -0,0 +1,47 @@
+package sector
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseDensity(t *testing.T) {
+	d, err := ParseDensity("sparse, F=cluster,k=RIFT")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d[0] != "sparse" || d[5] != "cluster" || d[10] != "rift" {
+		t.Fatalf("parsed wrongly: %v", d)
+	}
+	if actual := d.String(); actual != "sparse,F=cluster,K=rift" {
+		t.Fatalf("unexpected spec %q", actual)
+	}
+
+	for _, bad := range []string{"crowded", "Q=dense", "AB=rift"} {
+		if _, err := ParseDensity(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestDensityBySubsector(t *testing.T) {
+	d, _ := ParseDensity("standard,A=rift,P=cluster")
+	s := buildSector(Options{Seed: 31, Density: d})
+
+	counts := map[int]int{}
+	for _, hex := range s.hexes {
+		var hx, hy int
+		if _, err := fmt.Sscanf(hex.location, "%02d%02d", &hx, &hy); err != nil {
+			t.Fatal(err)
+		}
+		counts[subsectorOf(hx, hy)]++
+	}
+
+	if counts[0] >= counts[15] {
+		t.Fatalf("rift subsector A has %d worlds, cluster subsector P %d", counts[0], counts[15])
+	}
+	if s.comments[len(s.comments)-1] != " Density: standard,A=rift,P=cluster" {
+		t.Fatalf("density not recorded: %q", s.comments)
+	}
+}
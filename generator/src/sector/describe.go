@@ -0,0 +1,180 @@
+package sector
+
+import (
+	"fmt"
+	"strings"
+)
+
+var tradeCodeNames = map[tradeCode]string{
+	asteroid:         "Asteroid",
+	desert:           "Desert",
+	fluid:            "Fluid oceans",
+	garden:           "Garden",
+	hell:             "Hellworld",
+	iceCapped:        "Ice-capped",
+	ocean:            "Ocean world",
+	vacuum:           "Vacuum",
+	water:            "Water world",
+	satellite:        "Satellite",
+	locked:           "Tidally locked",
+	dieback:          "Dieback",
+	barren:           "Barren",
+	lowPop:           "Low population",
+	nonIndustrial:    "Non-industrial",
+	preHighPop:       "Pre-high population",
+	highPopulation:   "High population",
+	preAg:            "Pre-agricultural",
+	agricultural:     "Agricultural",
+	nonAg:            "Non-agricultural",
+	prisonExile:      "Prison, exile camp",
+	preIndustrial:    "Pre-industrial",
+	industrial:       "Industrial",
+	poor:             "Poor",
+	preRich:          "Pre-rich",
+	rich:             "Rich",
+	lowTech:          "Low technology",
+	highTech:         "High technology",
+	frozen:           "Frozen",
+	hot:              "Hot",
+	cold:             "Cold",
+	tropic:           "Tropic",
+	tundra:           "Tundra",
+	twilightZone:     "Twilight zone",
+	farming:          "Farming",
+	mining:           "Mining",
+	militaryRule:     "Military rule",
+	penalColony:      "Penal colony",
+	reserve:          "Reserve",
+	subsectorCapitol: "Subsector capital",
+	sectorCapitol:    "Sector capital",
+	capitol:          "Capital",
+	colony:           "Colony",
+	forbidden:        "Forbidden",
+	puzzle:           "Puzzle",
+	dangerous:        "Dangerous",
+	dataRepository:   "Data repository",
+	ancientSite:      "Ancient site",
+}
+
+// describeRemarks spells out each trade code, owner and sophont, leaving
+// anything unknown as written.
+func describeRemarks(remarks string) []string {
+	var described []string
+	for _, code := range strings.Fields(remarks) {
+		if name, ok := tradeCodeNames[tradeCode(code)]; ok {
+			described = append(described, fmt.Sprintf("%s: %s", code, name))
+		} else if location, ok := strings.CutPrefix(code, "O:"); ok {
+			described = append(described, fmt.Sprintf("%s: Owned by the world at %s", code, location))
+		} else if sophontRemark(code) {
+			described = append(described, fmt.Sprintf("%s: %s", code, describeSophont(code)))
+		} else {
+			described = append(described, code)
+		}
+	}
+	return described
+}
+
+// describeSophont spells out a sophont remark: a homeworld, as in (Akeed)7,
+// [Vargr] or Di(Droyne), or a share of the population, as in Luri3.
+func describeSophont(remark string) string {
+	share := func(digit string) string {
+		switch digit {
+		case "", "W":
+			return "all of the population"
+		case "0":
+			return "under 10% of the population"
+		}
+		return digit + "0% of the population"
+	}
+
+	switch {
+	case strings.HasPrefix(remark, "Di("):
+		return fmt.Sprintf("Homeworld of the extinct %s", strings.TrimSuffix(remark[3:], ")"))
+	case strings.HasPrefix(remark, "["):
+		name, digit, _ := strings.Cut(remark[1:], "]")
+		return fmt.Sprintf("Homeworld of the %s, a major race, %s", name, share(digit))
+	case strings.HasPrefix(remark, "("):
+		name, digit, _ := strings.Cut(remark[1:], ")")
+		return fmt.Sprintf("Homeworld of the %s, a minor race, %s", name, share(digit))
+	}
+	return fmt.Sprintf("%s sophonts, %s", remark[:4], share(remark[4:]))
+}
+
+var nobleTitleNames = map[nobleTitle]string{
+	knight:    "Knight",
+	baronet:   "Baronet",
+	baron:     "Baron",
+	marquis:   "Marquis",
+	viscount:  "Viscount",
+	count:     "Count",
+	duke:      "Duke",
+	grandDuke: "Grand Duke",
+	archduke:  "Archduke",
+}
+
+func describeNobility(titles []nobleTitle) string {
+	var names []string
+	for _, title := range titles {
+		if name, ok := nobleTitleNames[title]; ok {
+			names = append(names, name)
+		} else {
+			names = append(names, string(title))
+		}
+	}
+	if len(names) == 0 {
+		return "None"
+	}
+	return strings.Join(names, ", ")
+}
+
+var baseNames = map[baseLetter]string{
+	navalBase:      "Naval base",
+	navalDepot:     "Naval depot",
+	scoutBase:      "Scout base",
+	waystation:     "Scout waystation",
+	militaryBase:   "Military base",
+	scientificBase: "Scientific base",
+	diplomaticBase: "Diplomatic base",
+	culturalBase:   "Cultural base",
+	highport:       "Highport",
+	corsairBase:    "Corsair base",
+}
+
+func describeBases(bases string) string {
+	var names []string
+	for _, b := range bases {
+		if name, ok := baseNames[baseLetter(b)]; ok {
+			names = append(names, name)
+		} else {
+			names = append(names, string(b))
+		}
+	}
+	if len(names) == 0 {
+		return "None"
+	}
+	return strings.Join(names, ", ")
+}
+
+func describeZone(zone zoneType) string {
+	switch zone {
+	case amberZone:
+		return "Amber: travellers should exercise caution"
+	case redZone:
+		return "Red: interdicted"
+	}
+	return "Green: unrestricted"
+}
+
+func describeHzVar(hzVar int) string {
+	switch {
+	case hzVar < -1:
+		return "Inner system, far too hot"
+	case hzVar == -1:
+		return "Inner edge of the habitable zone, hot"
+	case hzVar == 1:
+		return "Outer edge of the habitable zone, cold"
+	case hzVar > 1:
+		return "Outer system, frozen"
+	}
+	return "Habitable zone"
+}
@@ -0,0 +1,131 @@
+package sector
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/violetexistence/traveller/shared/uwp"
+)
+
+// detailView spells out everything rolled for the selected world: the UWP
+// and world sheet, the extensions, each remark, and who the world answers
+// to.
+func (m model) detailView() string {
+	world, ok := m.selected()
+	if !ok {
+		return "No world selected"
+	}
+
+	lines := []string{
+		subsectorTitle.Render(fmt.Sprintf("%s %s %s", world.location, world.name, world.uwp)) + " in " + m.subsectorName(),
+		"",
+	}
+	for _, field := range worldFields {
+		line := field.describe(world)
+		switch field.label {
+		case "Economics", "Culture":
+			// Spelled out one by one below.
+			continue
+		case "Law level":
+			if getNumericUwpValue(world.uwp, Law) > 1 {
+				line += ", on top of everything lower levels ban"
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%-14s %s", field.label, line))
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, describeExtensions(world)...)
+
+	lines = append(lines, "", tableHeader.Render("Remarks"))
+	for _, remark := range describeRemarks(world.remarks) {
+		lines = append(lines, "  "+remark)
+	}
+	if len(world.remarks) == 0 {
+		lines = append(lines, "  None")
+	}
+
+	lines = append(lines, "", tableHeader.Render("Allegiance"))
+	allegiance := world.allegiance
+	if name, ok := m.sector.allegiances[world.allegiance]; ok {
+		allegiance += ": " + name
+	}
+	lines = append(lines, "  "+allegiance)
+	if owner, ok := m.sector.owner(world); ok {
+		lines = append(lines, fmt.Sprintf("  Colony of %s %s", owner.location, owner.name))
+	}
+	var colonies []string
+	for _, hex := range m.sector.hexes {
+		if owner, ok := m.sector.owner(hex); ok && owner.location == world.location {
+			colonies = append(colonies, hex.location+" "+hex.name)
+		}
+	}
+	if len(colonies) > 0 {
+		lines = append(lines, "  Owns "+strings.Join(colonies, ", "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// describeExtensions spells out the T5 extensions, nobility and PBG.
+func describeExtensions(w hexInfo) []string {
+	file := toFileWorld(w)
+	pbg := fmt.Sprintf("%s%s%s", uwp.Encode(w.populationMultiplier), uwp.Encode(w.belts), uwp.Encode(w.gasGiants))
+	return []string{
+		fmt.Sprintf("%-14s %s %s", "Importance", file.Ix, describeImportance(w.importance)),
+		fmt.Sprintf("%-14s %s", "Resources", describeScale(w.resources, "scarce", "modest", "plentiful", "abundant")),
+		fmt.Sprintf("%-14s %s", "Labour", describeLabor(w.labor)),
+		fmt.Sprintf("%-14s %s", "Infrastructure", describeScale(w.infrastructure, "little or none", "limited", "adequate", "extensive")),
+		fmt.Sprintf("%-14s %+d: %s", "Efficiency", w.efficiencies, describeLeaning(w.efficiencies, "wasteful", "average", "productive")),
+		fmt.Sprintf("%-14s %s", "Heterogeneity", describeScale(w.heterogeneity, "monolithic", "harmonious", "discordant", "fragmented")),
+		fmt.Sprintf("%-14s %s", "Acceptance", describeScale(w.acceptance, "xenophobic", "aloof", "friendly", "xenophilic")),
+		fmt.Sprintf("%-14s %s", "Strangeness", describeScale(w.strangeness, "familiar", "unusual", "confusing", "incomprehensible")),
+		fmt.Sprintf("%-14s %s", "Symbols", describeScale(w.symbols, "primitive", "simple", "sophisticated", "esoteric")),
+		fmt.Sprintf("%-14s %s", "Nobility", describeNobility(w.nobility)),
+		fmt.Sprintf("%-14s %s: %s", "PBG", pbg, describePBG(w)),
+	}
+}
+
+func describeLabor(labor int) string {
+	if labor == 0 {
+		return "0: no workforce to speak of"
+	}
+	return fmt.Sprintf("%s: a workforce of %s", uwp.Encode(labor), strings.ToLower(uwp.DescribePopulation(labor)))
+}
+
+func describeImportance(importance int) string {
+	switch {
+	case importance >= 4:
+		return "Very important"
+	case importance >= 2:
+		return "Important"
+	case importance >= 0:
+		return "Ordinary"
+	}
+	return "Unimportant"
+}
+
+// describeScale puts a T5 extension digit, which runs from 0 to about 15, in
+// one of four bands.
+func describeScale(value int, bands ...string) string {
+	return fmt.Sprintf("%s: %s", uwp.Encode(applyMinimum(value, 0)), bands[applyRange(value/4, 0, len(bands)-1)])
+}
+
+// describeLeaning names a value that runs either side of zero.
+func describeLeaning(value int, below string, even string, above string) string {
+	switch {
+	case value < 0:
+		return below
+	case value > 0:
+		return above
+	}
+	return even
+}
+
+func describePBG(w hexInfo) string {
+	people := "no permanent population"
+	if pop := getNumericUwpValue(w.uwp, Pop); pop > 0 && w.populationMultiplier > 0 {
+		people = fmt.Sprintf("%d x 10^%d people", w.populationMultiplier, pop)
+	}
+	return fmt.Sprintf("%s, %s, %s", people, plural(w.belts, "planetoid belt"), plural(w.gasGiants, "gas giant"))
+}
@@ -0,0 +1,57 @@
+package sector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/help"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestWorldDetail(t *testing.T) {
+	s := buildSector(Options{Seed: 2112})
+	var m tea.Model = model{sector: s, help: help.New()}
+
+	m = press(m, down, enter)
+	world, _ := m.(model).selected()
+	view := m.View()
+	for _, expected := range []string{
+		world.location + " " + world.name + " " + world.uwp,
+		"Starport",
+		"Law level",
+		"Labour",
+		"Nobility",
+		s.allegiances[world.allegiance],
+	} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("expected %q in the details:\n%s", expected, view)
+		}
+	}
+
+	m = press(m, down)
+	if next, _ := m.(model).selected(); !strings.Contains(m.View(), next.name) {
+		t.Error("expected the details to follow the cursor")
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.(model).detail || !strings.Contains(m.View(), "(1 of 16)") {
+		t.Fatal("expected escape to go back to the subsector")
+	}
+}
+
+func TestDescribeRemarks(t *testing.T) {
+	described := describeRemarks("Hi In Cp O:1108 (Akeed)7 Luri3 Di(Droyne) Xx")
+	expected := []string{
+		"Hi: High population",
+		"In: Industrial",
+		"Cp: Subsector capital",
+		"O:1108: Owned by the world at 1108",
+		"(Akeed)7: Homeworld of the Akeed, a minor race, 70% of the population",
+		"Luri3: Luri sophonts, 30% of the population",
+		"Di(Droyne): Homeworld of the extinct Droyne",
+		"Xx",
+	}
+	if strings.Join(described, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(described, "\n"))
+	}
+}
@@ -0,0 +1,209 @@
+package sector
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/violetexistence/traveller/shared/uwp"
+)
+
+// rederive brings everything that follows from a world's UWP, bases and
+// zone back into line after an edit. Trade codes, importance, labor,
+// acceptance, nobility and the zone remarks are worked out again. Rolled
+// values are kept while they still fit and rolled again when they do not.
+func (r *roller) rederive(hex hexInfo) hexInfo {
+	ignore := func(string, string, ...interface{}) {}
+
+	hex = r.lintTradeCodes(hex, ignore)
+	hex = dropZoneCodes(hex, ignore)
+
+	hex.importance = getImportanceExtension(hex)
+
+	hex.labor = getLabor(hex)
+	economics := lintEconomics(hex, ignore)
+	if economics.resources != hex.resources {
+		hex.resources = r.getResources(hex)
+	}
+	if economics.infrastructure != hex.infrastructure {
+		hex.infrastructure = r.getInfrastructure(hex)
+	}
+
+	hex.acceptance = getAcceptance(hex)
+	fits := lintCulture(hex, ignore)
+	if fits.heterogeneity != hex.heterogeneity {
+		hex.heterogeneity = r.getHeterogeneity(hex)
+	}
+	if fits.strangeness != hex.strangeness {
+		hex.strangeness = r.getStrangeness(hex)
+	}
+	if fits.symbols != hex.symbols {
+		hex.symbols = r.getSymbols(hex)
+	}
+
+	hex.nobility = getNobility(hex)
+
+	if fits := lintPBG(hex, ignore); fits.populationMultiplier != hex.populationMultiplier {
+		hex.populationMultiplier = r.getPopulationMultiplier(hex)
+	}
+
+	// The mainworld in the system listing is a copy of the world itself.
+	for i := range hex.system.bodies {
+		renameMainworld(&hex.system.bodies[i], hex)
+	}
+
+	return hex
+}
+
+func renameMainworld(b *body, hex hexInfo) {
+	if b.kind == mainworldBody {
+		b.name, b.uwp = hex.name, hex.uwp
+	}
+	for i := range b.satellites {
+		renameMainworld(&b.satellites[i], hex)
+	}
+}
+
+// worldAt finds the world in a hex.
+func (s sector) worldAt(location string) (hexInfo, bool) {
+	for _, hex := range s.hexes {
+		if hex.location == location {
+			return hex, true
+		}
+	}
+	return hexInfo{}, false
+}
+
+// withWorld puts a world in its hex, replacing whatever was there and
+// keeping the hexes in order.
+func (s sector) withWorld(world hexInfo) sector {
+	s.hexes = slices.Clone(s.hexes)
+	i, found := slices.BinarySearchFunc(s.hexes, world.location, func(h hexInfo, location string) int {
+		return strings.Compare(h.location, location)
+	})
+	if found {
+		s.hexes[i] = world
+	} else {
+		s.hexes = slices.Insert(s.hexes, i, world)
+	}
+	return s
+}
+
+// withoutWorld empties a hex.
+func (s sector) withoutWorld(location string) sector {
+	s.hexes = slices.DeleteFunc(slices.Clone(s.hexes), func(h hexInfo) bool {
+		return h.location == location
+	})
+	return s
+}
+
+// roller rolls for the whole sector or, given a hex, for a change to that
+// hex alone. It is seeded from the sector's seed and the hex, so the same
+// edit to the same sector always rolls the same.
+func (s sector) roller(location string) *roller {
+	seed := s.seed
+	for _, c := range location {
+		seed = seed*31 + int64(c)
+	}
+	return newRoller(seed, s.rules)
+}
+
+// rollEmptyHex rolls a new world for an empty hex in the background. The
+// roller is the command's alone, so nothing else rolls while it does.
+func rollEmptyHex(r *roller, location string, online bool) tea.Cmd {
+	return func() tea.Msg {
+		planets := newPlanets(newNameSource(r, online))
+		world := r.generateWorld(location, planets)
+		return rolledWorld{world: world, notice: planets.notice()}
+	}
+}
+
+// rerollSector rolls a new sector around the locked worlds, which stay as
+// they are apart from the capitals, polities, sophonts, owners and routes
+// being laid out again.
+func rerollSector(opts Options, locked []hexInfo) tea.Cmd {
+	return func() tea.Msg {
+		return buildSector(opts, locked...)
+	}
+}
+
+// unsettled is a world without the remarks that belonged to its place in
+// the old sector: the polity seat, its owner and its sophonts, all of which
+// are worked out afresh for the new one.
+func unsettled(world hexInfo) hexInfo {
+	world.remarks = strings.Join(slices.DeleteFunc(strings.Fields(withoutRemarks(world.remarks, capitol)), func(remark string) bool {
+		return strings.HasPrefix(remark, "O:") || sophontRemark(remark)
+	}), " ")
+	return world
+}
+
+// worldEdit is a world part way through the edit prompts, one field at a
+// time.
+type worldEdit struct {
+	world hexInfo
+	field int
+}
+
+// baseLetters lists every base a world can have, whichever rules put it
+// there.
+func baseLetters() string {
+	var letters []string
+	for letter := range baseNames {
+		letters = append(letters, string(letter))
+	}
+	slices.Sort(letters)
+	return strings.Join(letters, " ")
+}
+
+// editFields are the parts of a world that can be edited, in the order they
+// are asked for. Each takes the text typed at its prompt.
+var editFields = []struct {
+	name  string
+	limit int
+	get   func(hexInfo) string
+	set   func(*hexInfo, string) error
+}{
+	{"Name", 20, func(h hexInfo) string { return h.name }, func(h *hexInfo, text string) error {
+		if strings.TrimSpace(text) == "" {
+			return fmt.Errorf("a world needs a name")
+		}
+		h.name = strings.TrimSpace(text)
+		return nil
+	}},
+	{"UWP", 9, func(h hexInfo) string { return h.uwp }, func(h *hexInfo, text string) error {
+		profile, err := uwp.Parse(strings.ToUpper(text))
+		if err != nil {
+			return err
+		}
+		h.uwp = profile.String()
+		return nil
+	}},
+	{"Bases", 4, func(h hexInfo) string { return h.bases }, func(h *hexInfo, text string) error {
+		text = strings.ToUpper(text)
+		for _, b := range text {
+			if _, ok := baseNames[baseLetter(b)]; !ok {
+				return fmt.Errorf("%c is not a base: use one of %s", b, baseLetters())
+			}
+		}
+		h.bases = text
+		return nil
+	}},
+	{"Allegiance", 4, func(h hexInfo) string { return h.allegiance }, func(h *hexInfo, text string) error {
+		h.allegiance = strings.TrimSpace(text)
+		return nil
+	}},
+	{"Zone", 1, func(h hexInfo) string { return formatZone(h.zone) }, func(h *hexInfo, text string) error {
+		switch strings.ToUpper(text) {
+		case "", greenZone:
+			h.zone = greenZone
+		case amberZone:
+			h.zone = amberZone
+		case redZone:
+			h.zone = redZone
+		default:
+			return fmt.Errorf("zone is A, R or blank")
+		}
+		return nil
+	}},
+}
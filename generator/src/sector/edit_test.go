@@ -0,0 +1,142 @@
+package sector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/help"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func press(m tea.Model, keys ...tea.KeyMsg) tea.Model {
+	for _, k := range keys {
+		m, _ = m.Update(k)
+	}
+	return m
+}
+
+var (
+	enter = tea.KeyMsg{Type: tea.KeyEnter}
+	down  = tea.KeyMsg{Type: tea.KeyDown}
+)
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestEditWorld(t *testing.T) {
+	s := buildSector(Options{Seed: 2112})
+	var m tea.Model = model{sector: s, help: help.New()}
+	world, _ := m.(model).selected()
+
+	m = press(m, runes("c"), enter)
+	if prompt := m.(model).input.View(); !strings.Contains(prompt, world.location+" UWP") {
+		t.Fatalf("expected the UWP prompt after the name, got %q", prompt)
+	}
+
+	edited := m.(model)
+	edited.input.SetValue("not a uwp")
+	m = press(edited, enter)
+	if m.(model).err == nil || m.(model).prompt != editPrompt {
+		t.Fatal("expected a bad UWP to be refused and asked for again")
+	}
+
+	edited = m.(model)
+	edited.input.SetValue("a867a69-f")
+	m = press(edited, enter, runes("S"), enter, enter, enter)
+	if m.(model).prompt != noPrompt {
+		t.Fatal("expected the edit to be over after the zone")
+	}
+
+	after, _ := m.(model).sector.worldAt(world.location)
+	if after.uwp != "A867A69-F" || !strings.Contains(after.bases, "S") {
+		t.Fatalf("expected the edits to stick, got %s %q", after.uwp, after.bases)
+	}
+	for _, code := range []string{"Hi", "Ga"} {
+		if !strings.Contains(after.remarks, code) {
+			t.Errorf("expected %s in %q", code, after.remarks)
+		}
+	}
+	if after.importance != getImportanceExtension(after) || after.labor != 9 {
+		t.Errorf("expected Ix and labor worked out again, got { %d } and %d", after.importance, after.labor)
+	}
+	if after.name != world.name || len(after.nobility) == 0 {
+		t.Errorf("expected %s to keep its name and gain nobles, got %s %q", world.name, after.name, formatNobility(after.nobility))
+	}
+}
+
+func TestEditBases(t *testing.T) {
+	for _, field := range editFields {
+		if field.name != "Bases" {
+			continue
+		}
+
+		// MgT2 rolls highports and corsair bases as well as the T5 bases.
+		var hex hexInfo
+		if err := field.set(&hex, "nhr"); err != nil || hex.bases != "NHR" {
+			t.Fatalf("expected NHR to be taken, got %q %v", hex.bases, err)
+		}
+		if err := field.set(&hex, "Q"); err == nil || !strings.Contains(err.Error(), "C D E H M N P R S W") {
+			t.Fatalf("expected Q to be refused with the bases to choose from, got %v", err)
+		}
+		return
+	}
+	t.Fatal("no Bases field to edit")
+}
+
+func TestLockedWorldsSurviveReroll(t *testing.T) {
+	s := buildSector(Options{Seed: 2112})
+	var m tea.Model = model{sector: s, help: help.New()}
+
+	m = press(m, down, runes(" "))
+	locked, _ := m.(model).selected()
+	if !m.(model).locked[locked.location] {
+		t.Fatalf("expected %s to be locked", locked.location)
+	}
+
+	locked.name = "Hand Tuned"
+	locked.remarks = strings.TrimSpace(locked.remarks + " Cx O:3240 Luri3")
+	rerolled := rerollSector(Options{Seed: 7}, []hexInfo{locked})().(sector)
+	world, ok := rerolled.worldAt(locked.location)
+	if !ok || world.name != "Hand Tuned" || world.uwp != locked.uwp {
+		t.Fatalf("expected %s to survive the reroll, got %+v", locked.location, world)
+	}
+	if rerolled.seed != 7 {
+		t.Fatal("expected the rest of the sector rolled from the new seed")
+	}
+	if strings.Contains(world.remarks, "O:3240") || strings.Contains(world.remarks, "Luri3") {
+		t.Errorf("expected the old sector's remarks dropped, got %q", world.remarks)
+	}
+	if _, ok := rerolled.allegiances[world.allegiance]; !ok {
+		t.Errorf("expected %s to join the new sector's polities, got %q", world.name, world.allegiance)
+	}
+	if _, problems := rerolled.roller("").lintWorld(world, true); len(problems) > 0 {
+		t.Errorf("%s: %v", world.name, problems)
+	}
+}
+
+func TestAddAndRemoveWorlds(t *testing.T) {
+	s := buildSector(Options{Seed: 2112})
+	var m tea.Model = model{sector: s, help: help.New()}
+	first, _ := m.(model).selected()
+
+	m = press(m, runes("x"))
+	if _, ok := m.(model).sector.worldAt(first.location); ok || len(m.(model).sector.hexes) != len(s.hexes)-1 {
+		t.Fatalf("expected %s removed", first.location)
+	}
+
+	m = press(m, runes("a"), runes(first.location))
+	m, cmd := m.Update(enter)
+	if cmd == nil {
+		t.Fatalf("expected a world rolled for the empty hex %s", first.location)
+	}
+	m, _ = m.Update(cmd())
+	if added, ok := m.(model).selected(); !ok || added.location != first.location {
+		t.Fatalf("expected the new world in %s selected", first.location)
+	}
+
+	m = press(m, runes("a"), runes(first.location), enter)
+	if m.(model).err == nil {
+		t.Fatal("expected a taken hex to be refused")
+	}
+}
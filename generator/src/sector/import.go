@@ -0,0 +1,272 @@
+package sector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/violetexistence/traveller/generator/sectorfile"
+	"github.com/violetexistence/traveller/shared/uwp"
+)
+
+// openSector loads a sector file in the background.
+func openSector(path string) tea.Cmd {
+	return func() tea.Msg {
+		s, err := loadSector(path)
+		if err != nil {
+			return err
+		}
+		return s
+	}
+}
+
+// loadSector reads a sector file from disk. The sector is named for the file
+// and remembers where it came from so it can be saved back in place.
+func loadSector(path string) (sector, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return sector{}, err
+	}
+	defer f.Close()
+
+	file, err := sectorfile.Read(f)
+	if err != nil {
+		return sector{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	s, err := fromFile(file)
+	if err != nil {
+		return sector{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	s.name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	s.path = path
+	s.format = file.Format
+	if s.format == sectorfile.Legacy {
+		s.format = sectorfile.Column
+	}
+
+	if err := s.loadMetadata(metadataPath(path)); err != nil {
+		return sector{}, err
+	}
+
+	return s, nil
+}
+
+// fromFile decodes every world in a sector file.
+func fromFile(file sectorfile.File) (sector, error) {
+	// Edits work out trade codes by the rules the sector was made with.
+	s := sector{comments: file.Comments, rules: rulesOf(file.Comments)}
+
+	for _, c := range file.Comments {
+		if seed, ok := strings.CutPrefix(strings.TrimSpace(c), "Seed:"); ok {
+			s.seed, _ = strconv.ParseInt(strings.TrimSpace(seed), 10, 64)
+		}
+	}
+
+	// Files carry no system layout, so survey each system from the seed,
+	// keeping to what the PBG, W and remarks say about it.
+	r := s.roller("")
+
+	for _, w := range file.Worlds {
+		hex, err := fromFileWorld(w)
+		if err != nil {
+			return s, fmt.Errorf("%s %s: %w", w.Hex, w.Name, err)
+		}
+		hex.system = r.generateSystem(hex)
+		s.hexes = append(s.hexes, hex)
+	}
+
+	return s, nil
+}
+
+func fromFileWorld(w sectorfile.World) (hexInfo, error) {
+	profile, err := uwp.Parse(w.UWP)
+	if err != nil {
+		return hexInfo{location: w.Hex, name: w.Name}, fmt.Errorf("bad UWP: %w", err)
+	}
+
+	hex := hexInfo{
+		location:   w.Hex,
+		name:       w.Name,
+		uwp:        profile.String(),
+		bases:      w.Bases,
+		remarks:    w.Remarks,
+		zone:       greenZone,
+		allegiance: w.Allegiance,
+		stars:      w.Stars,
+		absent:     map[string]bool{},
+	}
+
+	if w.Zone != "" {
+		hex.zone = zoneType(w.Zone)
+	}
+
+	for _, title := range w.Nobility {
+		hex.nobility = append(hex.nobility, nobleTitle(title))
+	}
+
+	if primary, ok := parseStar(w.Stars); ok {
+		hex.primary = primary
+	}
+	hex.hzVar = climateHzVar(hex)
+	hex.system.mainworld = satelliteOrbit(hex)
+
+	decoders := []struct {
+		column string
+		value  string
+		decode func(string) error
+	}{
+		{"Ix", w.Ix, func(v string) error { return decodeImportance(v, &hex) }},
+		{"Ex", w.Ex, func(v string) error { return decodeEconomics(v, &hex) }},
+		{"Cx", w.Cx, func(v string) error { return decodeCulture(v, &hex) }},
+		{"PBG", w.PBG, func(v string) error { return decodePBG(v, &hex) }},
+		{"W", w.W, func(v string) (err error) {
+			hex.worlds, err = strconv.Atoi(v)
+			return err
+		}},
+	}
+
+	for _, d := range decoders {
+		if d.value == "" {
+			hex.absent[d.column] = true
+			continue
+		}
+		if err := d.decode(d.value); err != nil {
+			return hex, fmt.Errorf("bad %s %q: %w", d.column, d.value, err)
+		}
+	}
+
+	return hex, nil
+}
+
+// decodeImportance reads an importance extension such as "{ -1 }".
+func decodeImportance(value string, hex *hexInfo) error {
+	inner := strings.TrimSpace(strings.Trim(value, "{}"))
+	ix, err := strconv.Atoi(strings.TrimPrefix(inner, "+"))
+	if err != nil {
+		return err
+	}
+	hex.importance = ix
+	return nil
+}
+
+// decodeEconomics reads an economic extension such as "(B6B+3)".
+func decodeEconomics(value string, hex *hexInfo) error {
+	inner := strings.Trim(value, "()")
+	if len(inner) < 5 {
+		return fmt.Errorf("expected three digits and an efficiency")
+	}
+
+	digits, err := decodeEHexDigits(inner[:3])
+	if err != nil {
+		return err
+	}
+
+	efficiencies, err := strconv.Atoi(strings.TrimPrefix(inner[3:], "+"))
+	if err != nil {
+		return err
+	}
+
+	hex.resources, hex.labor, hex.infrastructure = digits[0], digits[1], digits[2]
+	hex.efficiencies = efficiencies
+	return nil
+}
+
+// decodeCulture reads a cultural extension such as "[896A]".
+func decodeCulture(value string, hex *hexInfo) error {
+	inner := strings.Trim(value, "[]")
+	if len(inner) != 4 {
+		return fmt.Errorf("expected four digits")
+	}
+
+	digits, err := decodeEHexDigits(inner)
+	if err != nil {
+		return err
+	}
+
+	hex.heterogeneity, hex.acceptance, hex.strangeness, hex.symbols = digits[0], digits[1], digits[2], digits[3]
+	return nil
+}
+
+// decodePBG reads the population multiplier, belts and gas giants.
+func decodePBG(value string, hex *hexInfo) error {
+	if len(value) != 3 {
+		return fmt.Errorf("expected three digits")
+	}
+
+	digits, err := decodeEHexDigits(value)
+	if err != nil {
+		return err
+	}
+
+	hex.populationMultiplier, hex.belts, hex.gasGiants = digits[0], digits[1], digits[2]
+	return nil
+}
+
+func decodeEHexDigits(value string) ([]int, error) {
+	var digits []int
+	for i := 0; i < len(value); i++ {
+		d, err := uwp.Decode(value[i])
+		if err != nil {
+			return nil, err
+		}
+		digits = append(digits, d)
+	}
+	return digits, nil
+}
+
+// parseStar reads the first star of a stellar string such as "K1 V K8 V".
+func parseStar(stars string) (star, bool) {
+	fields := strings.Fields(stars)
+	if len(fields) == 0 {
+		return star{}, false
+	}
+
+	if fields[0] == "D" || fields[0] == "BD" {
+		return star{class: spectralClass{letter: strings.TrimSuffix(fields[0], "D")}, size: "D"}, true
+	}
+
+	if len(fields) < 2 || len(fields[0]) != 2 {
+		return star{}, false
+	}
+
+	numeral, err := strconv.Atoi(fields[0][1:])
+	if err != nil {
+		return star{}, false
+	}
+
+	return star{
+		class: spectralClass{letter: fields[0][:1], numeral: numeral},
+		size:  fields[1],
+	}, true
+}
+
+// climateHzVar recovers the habitable zone variance that the climate trade
+// codes were derived from.
+func climateHzVar(hex hexInfo) int {
+	switch {
+	case hasTradeCode(frozen)(hex):
+		return 2
+	case includeTradeCodes(hot, tropic)(hex):
+		return -1
+	case includeTradeCodes(cold, tundra)(hex):
+		return 1
+	}
+	return 0
+}
+
+// satelliteOrbit recovers whether the mainworld is a satellite from its
+// trade codes.
+func satelliteOrbit(hex hexInfo) mainworldOrbit {
+	switch {
+	case hasTradeCode(locked)(hex):
+		return closeSatellite
+	case hasTradeCode(satellite)(hex):
+		return farSatellite
+	}
+	return planet
+}
@@ -0,0 +1,52 @@
+package sector
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/violetexistence/traveller/generator/sectorfile"
+)
+
+const importFixture = `# Seed: 99
+Hex  Name                 UWP       Remarks           {Ix}   (Ex)    [Cx]   N   B Z PBG W  A    Stellar  
+---- -------------------- --------- ----------------- ------ ------- ------ --- - - --- -- ---- ---------
+0407 Isolation            C7C2500-B Fl He Ni          { 0 }  (C44-4) [1516] -   - - 714 15 NaHu K1 V K8 V
+1101 Guntar               A8969AA-A Hi In Pz          { 4 }  (H8F+5) [BD7C] BcE - A 222 12 HoPA G5 V     
+1210 Inast                A663669-8 Ni Ri Da Mr(HoPA) { -1 } (B54+1) [7669] -   K A 712 13 NaHu F1 V M9 V
+`
+
+func TestImportRoundTrip(t *testing.T) {
+	file, err := sectorfile.Read(strings.NewReader(importFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := fromFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.seed != 99 {
+		t.Fatalf("expected seed 99, got %d", s.seed)
+	}
+
+	guntar := s.hexes[1]
+	if guntar.importance != 4 || guntar.resources != 17 || guntar.efficiencies != 5 || guntar.symbols != 12 {
+		t.Fatalf("extensions decoded wrongly: %+v", guntar)
+	}
+	if len(guntar.nobility) != 3 || guntar.nobility[2] != count {
+		t.Fatalf("nobility decoded wrongly: %v", guntar.nobility)
+	}
+	if guntar.primary.class.letter != "G" || guntar.primary.class.numeral != 5 || guntar.primary.size != "V" {
+		t.Fatalf("primary decoded wrongly: %+v", guntar.primary)
+	}
+
+	var out bytes.Buffer
+	if err := writeSector(&out, s, sectorfile.Column); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != importFixture {
+		t.Fatalf("re-saving changed the sector:\n%s", out.String())
+	}
+}
@@ -0,0 +1,322 @@
+package sector
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/violetexistence/traveller/shared/uwp"
+)
+
+// Problem is one place where a world disagrees with its own UWP.
+type Problem struct {
+	Hex     string
+	Name    string
+	Column  string
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s %s: %s %s", p.Hex, p.Name, p.Column, p.Message)
+}
+
+// Lint checks every world in a sector file against the rules the generator
+// rolls by: trade codes, importance, the economic and cultural extensions,
+// nobility, PBG against the worlds count and the zone. Each problem is
+// written to report. When fixed is not nil the sector is written to it
+// again with every problem fixed.
+func Lint(report io.Writer, fixed io.Writer, path string) ([]Problem, error) {
+	s, err := loadSector(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := s.roller("")
+
+	// Plenty of sector files leave nobility out entirely, which is no more
+	// a problem than leaving out the extensions.
+	nobility := slices.ContainsFunc(s.hexes, func(h hexInfo) bool { return len(h.nobility) > 0 })
+
+	var problems []Problem
+	for i, hex := range s.hexes {
+		var found []Problem
+		s.hexes[i], found = r.lintWorld(hex, nobility)
+		problems = append(problems, found...)
+	}
+
+	for _, p := range problems {
+		if _, err := fmt.Fprintln(report, p); err != nil {
+			return problems, err
+		}
+	}
+
+	if fixed != nil {
+		return problems, writeSector(fixed, s, s.format)
+	}
+
+	return problems, nil
+}
+
+// rulesOf finds the rule set a sector was generated with from its comments.
+func rulesOf(comments []string) RuleSet {
+	for _, c := range comments {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(c), "Rules:"); ok {
+			if set, err := ParseRuleSet(strings.TrimSpace(name)); err == nil {
+				return set
+			}
+		}
+	}
+	return T5
+}
+
+// lintWorld checks one world and returns it fixed. Each check works on the
+// world as the checks before it left it, so a missing Ag is added before
+// the Ix that counts it is checked.
+func (r *roller) lintWorld(hex hexInfo, nobility bool) (hexInfo, []Problem) {
+	var problems []Problem
+	report := func(column string, format string, args ...interface{}) {
+		problems = append(problems, Problem{hex.location, hex.name, column, fmt.Sprintf(format, args...)})
+	}
+
+	hex = r.lintTradeCodes(hex, report)
+	hex = r.lintZone(hex, report)
+
+	if !hex.absent["Ix"] {
+		if expected := getImportanceExtension(hex); hex.importance != expected {
+			report("Ix", "{ %d } should be { %d }", hex.importance, expected)
+			hex.importance = expected
+		}
+	}
+
+	if !hex.absent["Ex"] {
+		hex = lintEconomics(hex, report)
+	}
+
+	if !hex.absent["Cx"] {
+		hex = lintCulture(hex, report)
+	}
+
+	if nobility {
+		if expected := getNobility(hex); formatNobility(expected) != formatNobility(hex.nobility) {
+			report("Nobility", "%q should be %q", formatNobility(hex.nobility), formatNobility(expected))
+			hex.nobility = expected
+		}
+	}
+
+	if !hex.absent["PBG"] {
+		hex = lintPBG(hex, report)
+	}
+
+	return hex, problems
+}
+
+type reporter func(column string, format string, args ...interface{})
+
+// derivedCodes are the trade codes that follow from the UWP under the rules
+// in use. Any other remark is left alone.
+func (r *roller) derivedCodes() map[tradeCode]bool {
+	codes := map[tradeCode]bool{}
+	for _, def := range r.rules.tradeCodes {
+		codes[def.code] = true
+	}
+	if r.rules.extraCodes != nil {
+		codes[militaryRule] = true
+	}
+	return codes
+}
+
+// remarkCode is the trade code a remark stands for. Military rule may name
+// who rules, as in Mr(HoPA).
+func remarkCode(remark string) tradeCode {
+	if strings.HasPrefix(remark, string(militaryRule)+"(") {
+		return militaryRule
+	}
+	return tradeCode(remark)
+}
+
+func (r *roller) lintTradeCodes(hex hexInfo, report reporter) hexInfo {
+	derived := r.derivedCodes()
+	remarks := strings.Fields(hex.remarks)
+
+	// Work the codes out afresh, as the generator does, so that a code
+	// already present does not hide or suppress another.
+	bare := hex
+	bare.remarks = strings.Join(slices.DeleteFunc(slices.Clone(remarks), func(remark string) bool {
+		return derived[remarkCode(remark)]
+	}), " ")
+	codes := strings.Fields(r.getTradeCodes(bare))
+
+	expected := map[tradeCode]bool{}
+	for _, code := range codes {
+		expected[tradeCode(code)] = true
+	}
+
+	var kept []string
+	present := map[tradeCode]bool{}
+	for _, remark := range remarks {
+		code := remarkCode(remark)
+		if derived[code] && !expected[code] {
+			report("Remarks", "has %s but the UWP does not call for it", remark)
+			continue
+		}
+		present[code] = true
+		kept = append(kept, remark)
+	}
+
+	// Missing codes go after the last derived one, ahead of anything the
+	// referee added.
+	at := 0
+	for i, remark := range kept {
+		if derived[remarkCode(remark)] {
+			at = i + 1
+		}
+	}
+
+	var missing []string
+	for _, code := range codes {
+		if !present[tradeCode(code)] {
+			report("Remarks", "is missing %s", code)
+			missing = append(missing, code)
+		}
+	}
+
+	hex.remarks = strings.Join(slices.Insert(kept, at, missing...), " ")
+	return hex
+}
+
+// zoneCodes are the remarks that belong to a zone: Da or Pz for amber, Fo
+// for red.
+var zoneCodes = map[tradeCode]zoneType{
+	dangerous: amberZone,
+	puzzle:    amberZone,
+	forbidden: redZone,
+}
+
+// lintZone marks a green world the rules would have zoned, then drops any
+// zone remarks that disagree with the zone. An amber zone is the referee's
+// call and is never raised to red.
+func (r *roller) lintZone(hex hexInfo, report reporter) hexInfo {
+	// The rules only settle the zone when there is no roll for the true law
+	// level.
+	if hex.zone == greenZone && getNumericUwpValue(hex.uwp, Law) != 0xF {
+		if zone := r.rules.zone(r, hex); zone != greenZone {
+			report("Zone", "is green but the UWP calls for %s", describeZone(zone))
+			hex.zone = zone
+		}
+	}
+
+	return dropZoneCodes(hex, report)
+}
+
+// dropZoneCodes removes the zone remarks that disagree with the zone.
+func dropZoneCodes(hex hexInfo, report reporter) hexInfo {
+	remarks := slices.DeleteFunc(strings.Fields(hex.remarks), func(remark string) bool {
+		zone, ok := zoneCodes[tradeCode(remark)]
+		if ok && zone != hex.zone {
+			report("Remarks", "has %s but the world is %s", remark, describeZone(hex.zone))
+		}
+		return ok && zone != hex.zone
+	})
+	hex.remarks = strings.Join(remarks, " ")
+
+	return hex
+}
+
+// lintEconomics checks each digit of Ex against what could have been
+// rolled for it.
+func lintEconomics(hex hexInfo, report reporter) hexInfo {
+	population := getNumericUwpValue(hex.uwp, Pop)
+
+	resources := [2]int{2, 12}
+	if getNumericUwpValue(hex.uwp, TL) > 7 {
+		resources[1] += hex.gasGiants + hex.belts
+	}
+
+	labor := applyMinimum(population-1, 0)
+
+	var infrastructure [2]int
+	switch {
+	case population == 0:
+	case population < 4:
+		infrastructure = [2]int{hex.importance, hex.importance}
+	case population < 7:
+		infrastructure = [2]int{1 + hex.importance, 6 + hex.importance}
+	default:
+		infrastructure = [2]int{2 + hex.importance, 12 + hex.importance}
+	}
+	infrastructure = [2]int{applyRange(infrastructure[0], 0, 0xF), applyRange(infrastructure[1], 0, 0xF)}
+
+	hex.resources = lintDigit(report, "Ex", "resources", hex.resources, resources)
+	hex.labor = lintDigit(report, "Ex", "labor", hex.labor, [2]int{labor, labor})
+	hex.infrastructure = lintDigit(report, "Ex", "infrastructure", hex.infrastructure, infrastructure)
+
+	if hex.efficiencies < -5 || hex.efficiencies > 5 {
+		report("Ex", "efficiencies %+d is out of range -5 to +5", hex.efficiencies)
+		hex.efficiencies = applyRange(hex.efficiencies, -5, 5)
+	}
+
+	return hex
+}
+
+// lintCulture checks each digit of Cx. An empty world has no culture.
+func lintCulture(hex hexInfo, report reporter) hexInfo {
+	population := getNumericUwpValue(hex.uwp, Pop)
+	tech := getNumericUwpValue(hex.uwp, TL)
+
+	var heterogeneity, acceptance, strangeness, symbols [2]int
+	if population > 0 {
+		heterogeneity = [2]int{applyRange(population-5, 1, 0xF), applyRange(population+5, 1, 0xF)}
+		accepted := applyRange(population+hex.importance, 1, 0xF)
+		acceptance = [2]int{accepted, accepted}
+		strangeness = [2]int{1, 10}
+		symbols = [2]int{applyRange(tech-5, 1, 0xF), applyRange(tech+5, 1, 0xF)}
+	}
+
+	hex.heterogeneity = lintDigit(report, "Cx", "heterogeneity", hex.heterogeneity, heterogeneity)
+	hex.acceptance = lintDigit(report, "Cx", "acceptance", hex.acceptance, acceptance)
+	hex.strangeness = lintDigit(report, "Cx", "strangeness", hex.strangeness, strangeness)
+	hex.symbols = lintDigit(report, "Cx", "symbols", hex.symbols, symbols)
+
+	return hex
+}
+
+// lintDigit reports a digit outside its range and returns it moved to the
+// nearest end.
+func lintDigit(report reporter, column string, name string, value int, limits [2]int) int {
+	if value >= limits[0] && value <= limits[1] {
+		return value
+	}
+
+	if limits[0] == limits[1] {
+		report(column, "%s %s should be %s", name, uwp.Encode(value), uwp.Encode(limits[0]))
+	} else {
+		report(column, "%s %s is out of range %s-%s", name, uwp.Encode(value), uwp.Encode(limits[0]), uwp.Encode(limits[1]))
+	}
+	return applyRange(value, limits[0], limits[1])
+}
+
+// lintPBG checks that only an empty world has no population multiplier, and
+// that W counts the mainworld, belts and gas giants plus the 2D other worlds.
+func lintPBG(hex hexInfo, report reporter) hexInfo {
+	population := getNumericUwpValue(hex.uwp, Pop)
+
+	switch {
+	case population == 0 && hex.populationMultiplier != 0:
+		report("PBG", "population multiplier %d should be 0 for an empty world", hex.populationMultiplier)
+		hex.populationMultiplier = 0
+	case population > 0 && hex.populationMultiplier == 0:
+		report("PBG", "population multiplier 0 should be 1-9 for a populated world")
+		hex.populationMultiplier = 1
+	}
+
+	if !hex.absent["W"] {
+		least, most := 3+hex.belts+hex.gasGiants, 13+hex.belts+hex.gasGiants
+		if hex.worlds < least || hex.worlds > most {
+			report("W", "%d does not fit PBG %d%d%d, expected %d-%d", hex.worlds, hex.populationMultiplier, hex.belts, hex.gasGiants, least, most)
+			hex.worlds = applyRange(hex.worlds, least, most)
+		}
+	}
+
+	return hex
+}
@@ -0,0 +1,65 @@
+package sector
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/violetexistence/traveller/generator/sectorfile"
+)
+
+func TestLintGeneratedSector(t *testing.T) {
+	for _, rules := range []RuleSet{T5, MgT2, Classic} {
+		path := filepath.Join(t.TempDir(), "generated.sec")
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Generate(Output{Data: f}, Options{Seed: 1105, Rules: rules}, sectorfile.Tab); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+
+		problems, err := Lint(io.Discard, nil, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(problems) > 0 {
+			t.Errorf("%s sector has %d problems, the first: %s", rules, len(problems), problems[0])
+		}
+	}
+}
+
+func TestLintFix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "drift.sec")
+	drift := strings.Replace(importFixture, "Ni Ri Da Mr(HoPA) { -1 } (B54+1)", "Ni In Da Mr(HoPA) { 3 }  (B14+1)", 1)
+	if err := os.WriteFile(path, []byte(drift), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var report, fixed bytes.Buffer
+	problems, err := Lint(&report, &fixed, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"1210 Inast: Remarks has In", "1210 Inast: Ix { 3 }", "1210 Inast: Ex labor 1 should be 5"} {
+		if !strings.Contains(report.String(), expected) {
+			t.Errorf("expected the report to include %q:\n%s", expected, report.String())
+		}
+	}
+
+	if err := os.WriteFile(path, fixed.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	again, err := Lint(io.Discard, nil, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) > 0 {
+		t.Fatalf("fixing %d problems left %v", len(problems), again)
+	}
+}
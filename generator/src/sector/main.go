@@ -1,12 +1,10 @@
 package sector
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"math/rand"
-	"net/http"
 	"os"
 	"strconv"
 	"strings"
@@ -15,8 +13,12 @@ import (
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/violetexistence/traveller/generator/sectorfile"
+	"github.com/violetexistence/traveller/shared/hexmap"
+	"github.com/violetexistence/traveller/shared/uwp"
 )
 
 type worldSize int
@@ -174,15 +176,27 @@ const (
 )
 
 type sector struct {
-	name  string
-	hexes []hexInfo
+	name     string
+	seed     int64
+	rules    RuleSet // edition the worlds were rolled by
+	notice   string  // anything to tell about how the sector was rolled
+	hexes    []hexInfo
+	comments []string
+	path     string            // file the sector was opened from
+	format   sectorfile.Format // layout to save it back in
+
+	subsectors  []string          // names, A to P
+	allegiances map[string]string // names by allegiance code
+	routes      []sectorfile.Route
+	borders     []sectorfile.Border
+	stylesheet  string
 }
 
 type hexInfo struct {
 	name                 string
 	location             string
 	hzVar                int
-	uwp                  string
+	uwp                  string // as written in the sector file; built and checked with uwp.UWP
 	bases                string
 	remarks              string
 	zone                 zoneType
@@ -201,46 +215,156 @@ type hexInfo struct {
 	nobility             []nobleTitle
 	worlds               int
 	primary              star
+	companions           []companion
 	populationMultiplier int
 	belts                int
 	gasGiants            int
+	system               starSystem
+	absent               map[string]bool // columns an imported file left empty
 }
 
-var coin = newCoin()
+// roller is everything a roll depends on: the random source it draws from
+// and the edition whose tables it reads. Every sector, world and edit gets
+// its own, so that rolls made side by side cannot disturb one another and a
+// sector can always be regenerated from its seed.
+type roller struct {
+	rng   *rand.Rand
+	coin  *cointoss
+	rules rules
+}
+
+// newRoller starts a roller on the given seed and rule set.
+func newRoller(seed int64, set RuleSet) *roller {
+	rng := rand.New(rand.NewSource(seed))
+	return &roller{rng: rng, coin: newCoin(rng), rules: rulesFor(set)}
+}
+
+// newSeed picks a short seed that is easy to read out at the table.
+func newSeed() int64 {
+	return time.Now().UnixNano() % 1_000_000_000
+}
 
 type star struct {
 	class spectralClass
 	size  string
 }
 
-func getPrimary() star {
+func (r *roller) getPrimary() star {
+	primary, _, _ := r.rollPrimary()
+	return primary
+}
+
+// rollPrimary rolls the primary star, along with the flux behind its
+// spectral type and size that the other stars in the system are rolled from.
+func (r *roller) rollPrimary() (primary star, spectralFlux int, sizeFlux int) {
+	spectralFlux = r.flux()
 	class := spectralClass{
-		letter:  getSpectralType(flux()),
-		numeral: rollDecimal(0, 9),
+		letter:  r.getSpectralType(spectralFlux),
+		numeral: r.rollDecimal(0, 9),
 	}
 
-	size := getSpectralSize(class)
+	sizeFlux = r.flux()
 
 	return star{
 		class: class,
-		size:  size,
+		size:  spectralSize(class, sizeFlux),
+	}, spectralFlux, sizeFlux
+}
+
+type starPosition string
+
+const (
+	companionStar starPosition = "companion"
+	closeStar                  = "close"
+	nearStar                   = "near"
+	farStar                    = "far"
+)
+
+// companion is a star other than the primary. Close, near and far stars
+// orbit the primary; a companion is bound tightly to the star before it.
+type companion struct {
+	star     star
+	position starPosition
+	orbit    int
+}
+
+// getCompanions rolls the other stars in the system. Each position, and a
+// companion for every star present, turns up on a flux of 3 or more. Their
+// types follow on from the primary's flux, so they are as bright as it or
+// dimmer.
+func (r *roller) getCompanions(spectralFlux int, sizeFlux int) []companion {
+	var companions []companion
+
+	roll := func(position starPosition, orbit int) {
+		class := spectralClass{
+			letter:  r.getSpectralType(applyRange(spectralFlux+r.dice(1)-1, -6, 6)),
+			numeral: r.rollDecimal(0, 9),
+		}
+		companions = append(companions, companion{
+			star:     star{class: class, size: spectralSize(class, applyRange(sizeFlux+r.dice(1)+2, -6, 6))},
+			position: position,
+			orbit:    orbit,
+		})
+	}
+
+	if r.flux() > 2 {
+		roll(companionStar, 0)
 	}
+
+	for _, p := range []struct {
+		position starPosition
+		orbit    int
+	}{
+		{closeStar, -1},
+		{nearStar, 5},
+		{farStar, 11},
+	} {
+		if r.flux() > 2 {
+			orbit := r.dice(1) + p.orbit
+			roll(p.position, orbit)
+			if r.flux() > 2 {
+				roll(companionStar, orbit)
+			}
+		}
+	}
+
+	return companions
 }
 
-func rollDecimal(min int, max int) int {
+// formatStars writes the primary and its companions in T5 order.
+func formatStars(primary star, companions []companion) string {
+	stars := []string{formatStar(primary)}
+	for _, c := range companions {
+		stars = append(stars, formatStar(c.star))
+	}
+	return strings.Join(stars, " ")
+}
+
+// formatStar writes a star the way the Stars column expects it.
+func formatStar(s star) string {
+	if s.size == "D" {
+		if s.class.letter == "B" {
+			return "BD"
+		}
+		return "D"
+	}
+	return fmt.Sprintf("%s%d %s", s.class.letter, s.class.numeral, s.size)
+}
+
+func (r *roller) rollDecimal(min int, max int) int {
 	if max <= min {
 		panic(fmt.Sprintf("max must be greater than min {%d, %d}", min, max))
 	}
 
-	return rand.Intn(max-min+1) + min
+	return r.rng.Intn(max-min+1) + min
 }
 
-func getSpectralType(fluxValue int) string {
+func (r *roller) getSpectralType(fluxValue int) string {
 	row := fluxValue + 6
 	spectralType := spectralInfoMatrix[row][0]
 
 	if spectralType == "OB" {
-		if coin.Toss() {
+		if r.coin.Toss() {
 			spectralType = "O"
 		} else {
 			spectralType = "B"
@@ -282,8 +406,12 @@ type spectralClass struct {
 	numeral int    // 0-9
 }
 
-func getSpectralSize(class spectralClass) string {
-	row := flux() + 6
+func (r *roller) getSpectralSize(class spectralClass) string {
+	return spectralSize(class, r.flux())
+}
+
+func spectralSize(class spectralClass, fluxValue int) string {
+	row := fluxValue + 6
 	col := spectralSizeMatrixColumns[class.letter]
 	size := spectralInfoMatrix[row][col]
 
@@ -300,7 +428,9 @@ func getSpectralSize(class spectralClass) string {
 	return size
 }
 
-func getHzVar(star star) int {
+// getHzVar places the mainworld relative to the habitable zone. A companion
+// to the primary or a close star adds light, pushing worlds warmer.
+func (r *roller) getHzVar(star star, companions ...companion) int {
 	hzVar := 0
 	dm := 0
 
@@ -311,7 +441,13 @@ func getHzVar(star star) int {
 		dm -= 2
 	}
 
-	roll := flux() + dm
+	for _, c := range companions {
+		if c.orbit < 6 && (c.position == companionStar || c.position == closeStar) {
+			dm--
+		}
+	}
+
+	roll := r.flux() + dm
 
 	switch {
 	case roll < -5:
@@ -335,16 +471,16 @@ const (
 	redZone   = "R"
 )
 
-func getWorlds(hex hexInfo) int {
-	return 1 + hex.gasGiants + hex.belts + dice(2)
+func (r *roller) getWorlds(hex hexInfo) int {
+	return 1 + hex.gasGiants + hex.belts + r.dice(2)
 }
 
-func getHeterogeneity(hex hexInfo) int {
+func (r *roller) getHeterogeneity(hex hexInfo) int {
 	pop := getNumericUwpValue(hex.uwp, Pop)
 	if pop == 0 {
 		return 0
 	}
-	return applyRange(pop+flux(), 1, 0xF)
+	return applyRange(pop+r.flux(), 1, 0xF)
 }
 
 func getAcceptance(hex hexInfo) int {
@@ -355,27 +491,27 @@ func getAcceptance(hex hexInfo) int {
 	return applyRange(pop+hex.importance, 1, 0xF)
 }
 
-func getStrangeness(hex hexInfo) int {
+func (r *roller) getStrangeness(hex hexInfo) int {
 	pop := getNumericUwpValue(hex.uwp, Pop)
 	if pop == 0 {
 		return 0
 	}
-	return applyMinimum(flux()+5, 1)
+	return applyMinimum(r.flux()+5, 1)
 }
 
-func getSymbols(hex hexInfo) int {
+func (r *roller) getSymbols(hex hexInfo) int {
 	pop := getNumericUwpValue(hex.uwp, Pop)
 	if pop == 0 {
 		return 0
 	}
-	return applyRange(flux()+getNumericUwpValue(hex.uwp, TL), 1, 0xF)
+	return applyRange(r.flux()+getNumericUwpValue(hex.uwp, TL), 1, 0xF)
 }
 
-func getZone(hex hexInfo) zoneType {
+func (r *roller) getZone(hex hexInfo) zoneType {
 	starport := string(hex.uwp[St])
 	trueLawLevel := getNumericUwpValue(hex.uwp, Law)
 	if trueLawLevel == 0xF {
-		roll := rollDecimal(1, 4)
+		roll := r.rollDecimal(1, 4)
 		switch roll {
 		case 1, 2, 3:
 			trueLawLevel += roll
@@ -397,8 +533,16 @@ func getZone(hex hexInfo) zoneType {
 	return greenZone
 }
 
-func getNumericUwpValue(uwp string, element uwpElementType) int {
-	return decodeHex(string(uwp[element]))
+// getNumericUwpValue reads one eHex digit of a UWP, or uwp.Unknown for a "?".
+// Worlds keep their UWP as the string the sector file holds, so that one
+// read with a digit out of range is written back as it was; uwp.Parse is
+// for checking it.
+func getNumericUwpValue(profile string, element uwpElementType) int {
+	value, err := uwp.Decode(profile[element])
+	if err != nil {
+		return uwp.Unknown
+	}
+	return value
 }
 
 func newSpinner() spinner.Model {
@@ -409,66 +553,464 @@ func newSpinner() spinner.Model {
 	return s
 }
 
-func New() tea.Model {
+type promptKind int
+
+const (
+	noPrompt promptKind = iota
+	seedPrompt
+	openPrompt
+	hexPrompt
+	addPrompt
+	editPrompt
+)
+
+func newPrompt(kind promptKind) textinput.Model {
+	t := textinput.New()
+
+	switch kind {
+	case seedPrompt:
+		t.Placeholder = "random"
+		t.Prompt = "Seed: "
+		t.CharLimit = 19
+		t.Validate = func(s string) error {
+			if s == "" {
+				return nil
+			}
+			_, err := strconv.ParseInt(s, 10, 64)
+			return err
+		}
+	case openPrompt:
+		t.Placeholder = "sector.data"
+		t.Prompt = "Open: "
+	case hexPrompt:
+		t.Placeholder = "0101"
+		t.Prompt = "Hex: "
+		t.CharLimit = 4
+	case addPrompt:
+		t.Placeholder = "0101"
+		t.Prompt = "Add a world in hex: "
+		t.CharLimit = 4
+	}
+
+	return t
+}
+
+// newEditPrompt asks for the next field of a world being edited, starting
+// from what it holds now.
+func newEditPrompt(edit worldEdit) textinput.Model {
+	field := editFields[edit.field]
+
+	t := textinput.New()
+	t.Prompt = fmt.Sprintf("%s %s: ", edit.world.location, field.name)
+	t.CharLimit = field.limit
+	t.SetValue(field.get(edit.world))
+
+	return t
+}
+
+// Options controls how a sector is generated.
+type Options struct {
+	Seed    int64   // zero picks a random seed
+	Name    string  // empty rolls a name
+	Online  bool    // fetch names from donjon.bin.sh, falling back to the offline generator
+	Path    string  // open this sector file instead of generating one
+	Density Density // presets by subsector, empty for the default
+	Rules   RuleSet // world creation tables, empty for T5
+	// Sophonts are the species settled across the sector, nil for the
+	// sophonts of the Gateway region.
+	Sophonts []Sophont
+}
+
+// Output says where Generate writes. Only Data is required.
+type Output struct {
+	Data     io.Writer
+	Metadata io.Writer // sector metadata XML
+	Systems  io.Writer // a listing of every star system
+	Map      io.Writer // an SVG map of the sector
+	Status   io.Writer // notices, such as names falling back to offline
+	// MapSubsector limits the map to one subsector, A to P.
+	MapSubsector string
+}
+
+// Generate rolls a sector, or opens the one at opts.Path, and writes it out
+// without any user interface.
+func Generate(out Output, opts Options, format sectorfile.Format) error {
+	var s sector
+	if opts.Path != "" {
+		var err error
+		if s, err = loadSector(opts.Path); err != nil {
+			return err
+		}
+	} else {
+		if opts.Seed == 0 {
+			opts.Seed = newSeed()
+		}
+		s = buildSector(opts)
+	}
+	if out.Status != nil && s.notice != "" {
+		fmt.Fprintln(out.Status, s.notice)
+	}
+
+	if err := writeSector(out.Data, s, format); err != nil {
+		return err
+	}
+	if out.Metadata != nil {
+		if err := sectorfile.WriteMetadata(out.Metadata, s.metadata()); err != nil {
+			return err
+		}
+	}
+	if out.Systems != nil {
+		if err := writeSystems(out.Systems, s); err != nil {
+			return err
+		}
+	}
+	if out.Map != nil {
+		return writeMap(out.Map, s, out.MapSubsector)
+	}
+	return nil
+}
+
+// GenerateWorld rolls a single world in the given hex and writes it to w as
+// a one world sector. Notices go to status, if it is not nil.
+func GenerateWorld(w io.Writer, status io.Writer, opts Options, hex string, format sectorfile.Format) error {
+	if opts.Seed == 0 {
+		opts.Seed = newSeed()
+	}
+	r := newRoller(opts.Seed, opts.Rules)
+
+	planets := newPlanets(newNameSource(r, opts.Online))
+	world := r.generateWorld(hex, planets)
+	if opts.Name != "" {
+		world.name = opts.Name
+	}
+	if notice := planets.notice(); status != nil && notice != "" {
+		fmt.Fprintln(status, notice)
+	}
+
+	return writeSector(w, sector{
+		name:     world.name,
+		seed:     opts.Seed,
+		hexes:    []hexInfo{world},
+		comments: []string{fmt.Sprintf(" Seed: %d", opts.Seed)},
+	}, format)
+}
+
+// New creates the sector generator.
+func New(opts Options) tea.Model {
+	if opts.Seed == 0 {
+		opts.Seed = newSeed()
+	}
+
 	return model{
 		help:    help.New(),
 		spinner: newSpinner(),
 		waiting: true,
 		message: "Generating sector data...",
+		opts:    opts,
 	}
 }
 
 type model struct {
 	help    help.Model
 	spinner spinner.Model
+	input   textinput.Model
+	prompt  promptKind
 	waiting bool
 	message string
+	err     error
+	notice  string // shown below the sector until the next one
+	opts    Options
 	sector  sector
 	sub     int
+	system  string // hex whose star system is on show
+	showMap bool
+	cursor  int             // selected world in the subsector
+	detail  bool            // whether the selected world is spelled out
+	locked  map[string]bool // hexes kept when the sector is rerolled
+	edit    worldEdit
 }
 
 type keyMap struct {
-	Prev key.Binding
-	Next key.Binding
-	Save key.Binding
+	Prev   key.Binding
+	Next   key.Binding
+	Up     key.Binding
+	Down   key.Binding
+	Save   key.Binding
+	Reroll key.Binding
+	Seed   key.Binding
+	Open   key.Binding
+	System key.Binding
+	Map    key.Binding
+	Edit   key.Binding
+	Lock   key.Binding
+	Add    key.Binding
+	Remove key.Binding
+	Detail key.Binding
+	Cancel key.Binding
 }
 
 func (k keyMap) shortHelp() []key.Binding {
-	return []key.Binding{k.Prev, k.Next, k.Save}
+	return []key.Binding{k.Prev, k.Next, k.Up, k.Down, k.Save, k.Reroll, k.Seed, k.Open, k.System, k.Map, k.Edit, k.Lock, k.Add, k.Remove, k.Detail}
 }
 
 var defaultKeyMap = keyMap{
 	Prev: key.NewBinding(
 		key.WithKeys("h", "left"),
-		key.WithHelp("←/h", "prev"),
+		key.WithHelp("←/h", "prev subsector"),
 	),
 	Next: key.NewBinding(
 		key.WithKeys("l", "right"),
-		key.WithHelp("→/l", "next"),
+		key.WithHelp("→/l", "next subsector"),
+	),
+	Up: key.NewBinding(
+		key.WithKeys("k", "up"),
+		key.WithHelp("↑/k", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("j", "down"),
+		key.WithHelp("↓/j", "down"),
 	),
 	Save: key.NewBinding(
 		key.WithKeys("s"),
 		key.WithHelp("s", "save"),
 	),
+	Reroll: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "reroll"),
+	),
+	Seed: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "enter seed"),
+	),
+	Open: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "open"),
+	),
+	System: key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y", "system"),
+	),
+	Map: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "map"),
+	),
+	Edit: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "change world"),
+	),
+	Lock: key.NewBinding(
+		key.WithKeys(" "),
+		key.WithHelp("space", "lock"),
+	),
+	Add: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "add world"),
+	),
+	Remove: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "remove world"),
+	),
+	Detail: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "details"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "cancel"),
+	),
 }
 
 func (m model) Init() tea.Cmd {
+	if m.opts.Path != "" {
+		return tea.Batch(
+			m.spinner.Tick,
+			openSector(m.opts.Path),
+		)
+	}
+
 	return tea.Batch(
 		m.spinner.Tick,
-		generateSector(),
+		generateSector(m.opts),
 	)
 }
 
+// regenerate rolls a new sector from seed, keeping the locked worlds.
+func (m model) regenerate(seed int64) (model, tea.Cmd) {
+	m.opts.Seed = seed
+	m.opts.Path = ""
+	m.sub = 0
+	m.cursor = 0
+	m.waiting = true
+	m.message = "Generating sector data..."
+
+	var locked []hexInfo
+	for _, hex := range m.sector.hexes {
+		if m.locked[hex.location] {
+			locked = append(locked, hex)
+		}
+	}
+
+	return m, tea.Batch(
+		m.spinner.Tick,
+		rerollSector(m.opts, locked),
+	)
+}
+
+// selected is the world under the cursor, if the subsector has any.
+func (m model) selected() (hexInfo, bool) {
+	worlds := m.sector.subsector(m.sub)
+	if len(worlds) == 0 {
+		return hexInfo{}, false
+	}
+	return worlds[min(m.cursor, len(worlds)-1)], true
+}
+
+// finishEdit takes the answer to one edit prompt and moves on to the next
+// field. After the last the world is put back with everything that depends
+// on what changed worked out again.
+func (m model) finishEdit() (model, tea.Cmd) {
+	if err := editFields[m.edit.field].set(&m.edit.world, m.input.Value()); err != nil {
+		m.err = err
+		return m, nil
+	}
+	m.err = nil
+
+	m.edit.field++
+	if m.edit.field < len(editFields) {
+		m.input = newEditPrompt(m.edit)
+		return m, m.input.Focus()
+	}
+
+	m.prompt = noPrompt
+	m.sector = m.sector.withWorld(m.sector.roller(m.edit.world.location).rederive(m.edit.world))
+	return m, nil
+}
+
+// Busy is true while a prompt or the detail panel is open, when Esc and q
+// belong to the sector generator rather than taking it back to the menu.
+func (m model) Busy() bool {
+	return m.prompt != noPrompt || m.detail
+}
+
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
+	if m.prompt != noPrompt {
+		if msg, ok := msg.(tea.KeyMsg); ok && key.Matches(msg, defaultKeyMap.Cancel) {
+			m.prompt = noPrompt
+			m.err = nil
+			return m, nil
+		}
+		if msg, ok := msg.(tea.KeyMsg); ok && msg.Type == tea.KeyEnter && m.prompt == editPrompt {
+			return m.finishEdit()
+		}
+		if msg, ok := msg.(tea.KeyMsg); ok && msg.Type == tea.KeyEnter {
+			kind := m.prompt
+			m.prompt = noPrompt
+			m.input.Blur()
+			if m.input.Value() == "" || m.input.Err != nil {
+				return m, nil
+			}
+
+			switch kind {
+			case seedPrompt:
+				seed, _ := strconv.ParseInt(m.input.Value(), 10, 64)
+				return m.regenerate(seed)
+			case openPrompt:
+				m.opts.Path = m.input.Value()
+				m.sub = 0
+				m.cursor = 0
+				m.locked = nil
+				m.waiting = true
+				m.message = "Opening sector data..."
+				return m, m.Init()
+			case hexPrompt:
+				m.system = m.input.Value()
+				return m, nil
+			case addPrompt:
+				location := m.input.Value()
+				if _, _, ok := parseLocationCode(location); !ok {
+					m.err = fmt.Errorf("%s is not a hex in the sector", location)
+					return m, nil
+				}
+				if world, taken := m.sector.worldAt(location); taken {
+					m.err = fmt.Errorf("%s is already taken by %s", location, world.name)
+					return m, nil
+				}
+				return m, rollEmptyHex(m.sector.roller(location), location, m.opts.Online)
+			}
+		}
+
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.waiting {
+			break
+		}
+
 		switch {
+		case key.Matches(msg, defaultKeyMap.Reroll):
+			return m.regenerate(newSeed())
+		case key.Matches(msg, defaultKeyMap.Seed):
+			m.prompt = seedPrompt
+			m.input = newPrompt(seedPrompt)
+			return m, m.input.Focus()
+		case key.Matches(msg, defaultKeyMap.Open):
+			m.prompt = openPrompt
+			m.input = newPrompt(openPrompt)
+			return m, m.input.Focus()
+		case key.Matches(msg, defaultKeyMap.System):
+			if m.system != "" {
+				m.system = ""
+				return m, nil
+			}
+			m.prompt = hexPrompt
+			m.input = newPrompt(hexPrompt)
+			return m, m.input.Focus()
+		case key.Matches(msg, defaultKeyMap.Map):
+			m.showMap = !m.showMap
+		case key.Matches(msg, defaultKeyMap.Detail):
+			m.detail = !m.detail
+		case key.Matches(msg, defaultKeyMap.Cancel):
+			m.detail = false
 		case key.Matches(msg, defaultKeyMap.Prev):
 			m.sub = applyMinimum(m.sub-1, 0)
+			m.cursor = 0
 		case key.Matches(msg, defaultKeyMap.Next):
 			m.sub = applyMaximum(m.sub+1, 15)
+			m.cursor = 0
+		case key.Matches(msg, defaultKeyMap.Up):
+			m.cursor = applyMinimum(m.cursor-1, 0)
+		case key.Matches(msg, defaultKeyMap.Down):
+			m.cursor = applyRange(m.cursor+1, 0, applyMinimum(len(m.sector.subsector(m.sub))-1, 0))
+		case key.Matches(msg, defaultKeyMap.Edit):
+			if world, ok := m.selected(); ok {
+				m.edit = worldEdit{world: world}
+				m.prompt = editPrompt
+				m.input = newEditPrompt(m.edit)
+				return m, m.input.Focus()
+			}
+		case key.Matches(msg, defaultKeyMap.Lock):
+			if world, ok := m.selected(); ok {
+				if m.locked == nil {
+					m.locked = map[string]bool{}
+				}
+				m.locked[world.location] = !m.locked[world.location]
+			}
+		case key.Matches(msg, defaultKeyMap.Add):
+			m.prompt = addPrompt
+			m.input = newPrompt(addPrompt)
+			return m, m.input.Focus()
+		case key.Matches(msg, defaultKeyMap.Remove):
+			if world, ok := m.selected(); ok {
+				m.sector = m.sector.withoutWorld(world.location)
+				delete(m.locked, world.location)
+				m.cursor = applyMinimum(m.cursor-1, 0)
+			}
 		case key.Matches(msg, defaultKeyMap.Save):
 			m.waiting = true
 			m.message = "Saving sector data..."
@@ -477,8 +1019,26 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				saveSector(m.sector),
 			)
 		}
+	case rolledWorld:
+		// A world rolled for an empty hex: show it selected.
+		m.sector = m.sector.withWorld(msg.world)
+		m.notice = msg.notice
+		x, y, _ := parseLocationCode(msg.world.location)
+		m.sub = subsectorOf(x, y)
+		for i, world := range m.sector.subsector(m.sub) {
+			if world.location == msg.world.location {
+				m.cursor = i
+			}
+		}
 	case sector:
 		m.sector = msg
+		m.notice = msg.notice
+		m.system = ""
+		m.err = nil
+		m.waiting = false
+		m.spinner = newSpinner()
+	case error:
+		m.err = msg
 		m.waiting = false
 		m.spinner = newSpinner()
 	case saveSuccessful:
@@ -497,104 +1057,325 @@ func (m model) View() string {
 	if m.waiting {
 		return fmt.Sprintf("\n\n%s %s", m.spinner.View(), m.message)
 	} else {
-		str := fmt.Sprintf("\n\n%s Sector\n", m.sector.name)
-		for _, world := range m.sector.hexes {
-			str += fmt.Sprintf("\n%s %-20s %s", world.location, world.name, world.uwp)
+		str := fmt.Sprintf("\n\n%s Sector (seed %d)\n", m.sector.name, m.sector.seed)
+		switch {
+		case m.system != "":
+			str += "\n" + m.systemView()
+		case m.detail:
+			str += "\n" + m.detailView()
+		case m.showMap:
+			str += "\n" + m.mapView()
+		default:
+			str += "\n" + m.subsectorView()
+		}
+		if m.err != nil {
+			str += fmt.Sprintf("\n\n %v", m.err)
+		}
+		if m.notice != "" {
+			str += fmt.Sprintf("\n\n %s", m.notice)
+		}
+		if m.prompt != noPrompt {
+			str += fmt.Sprintf("\n\n %s", m.input.View())
 		}
 		str += fmt.Sprintf("\n %s", m.help.ShortHelpView(defaultKeyMap.shortHelp()))
 		return str
 	}
 }
 
-func generateSector() tea.Cmd {
+// systemView lists the star system in the hex picked at the prompt.
+func (m model) systemView() string {
+	for _, hex := range m.sector.hexes {
+		if hex.location == m.system {
+			return hex.location + " " + strings.Join(describeSystem(hex), "\n")
+		}
+	}
+	return fmt.Sprintf("No world in hex %s", m.system)
+}
+
+var subsectorTitle = lipgloss.NewStyle().Bold(true)
+
+var tableHeader = lipgloss.NewStyle().Faint(true)
+
+var selectedRow = lipgloss.NewStyle().Reverse(true)
+
+// subsectorView is a page for the current subsector: its name, how many
+// worlds it holds and a row for each of them. Locked worlds are starred.
+func (m model) subsectorView() string {
+	worlds := m.sector.subsector(m.sub)
+
+	remarksWidth := len("Remarks")
+	for _, world := range worlds {
+		remarksWidth = max(remarksWidth, len(world.remarks))
+	}
+
+	row := func(columns ...string) string {
+		return fmt.Sprintf("%1s %-4s %-20s %-9s %-2s %-*s %-1s %-3s %-4s %s", columns[0], columns[1], columns[2], columns[3], columns[4], remarksWidth, columns[5], columns[6], columns[7], columns[8], columns[9])
+	}
+
+	lines := []string{
+		subsectorTitle.Render(m.subsectorName()) + fmt.Sprintf(" (%d of 16), %s", m.sub+1, plural(len(worlds), "world")),
+		"",
+		tableHeader.Render(row("", "Hex", "Name", "UWP", "B", "Remarks", "Z", "PBG", "A", "Stellar")),
+	}
+	for i, world := range worlds {
+		lock := ""
+		if m.locked[world.location] {
+			lock = "*"
+		}
+		pbg := fmt.Sprintf("%s%s%s", uwp.Encode(world.populationMultiplier), uwp.Encode(world.belts), uwp.Encode(world.gasGiants))
+		line := row(lock, world.location, world.name, world.uwp, world.bases, world.remarks, formatZone(world.zone), pbg, world.allegiance, world.stars)
+		if i == min(m.cursor, len(worlds)-1) {
+			line = selectedRow.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	if len(worlds) == 0 {
+		lines = append(lines, "No worlds")
+	}
+
+	if routes := m.sector.routesIn(m.sub); len(routes) > 0 {
+		lines = append(lines, "", tableHeader.Render("Routes"))
+		lines = append(lines, routes...)
+	}
+
+	if colonies := m.sector.coloniesIn(m.sub); len(colonies) > 0 {
+		lines = append(lines, "", tableHeader.Render("Colonies"))
+		lines = append(lines, colonies...)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (m model) subsectorName() string {
+	name := ""
+	if m.sub < len(m.sector.subsectors) {
+		name = m.sector.subsectors[m.sub]
+	}
+	return fmt.Sprintf("Subsector %c: %s", 'A'+m.sub, name)
+}
+
+// mapView draws the current subsector as a hex grid.
+func (m model) mapView() string {
+	var worlds []hexmap.World
+	for _, hex := range m.sector.subsector(m.sub) {
+		worlds = append(worlds, hexmap.World{
+			Hex:      hex.location,
+			Name:     hex.name,
+			Starport: hex.uwp[St],
+			GasGiant: hex.gasGiants > 0,
+			Bases:    hex.bases,
+			Zone:     formatZone(hex.zone),
+		})
+	}
+
+	return subsectorTitle.Render(m.subsectorName()) + "\n" +
+		hexmap.Subsector(m.sub, worlds) + "\n" + hexmap.Legend
+}
+
+// subsector lists the worlds in one subsector, 0 to 15, in hex order.
+func (s sector) subsector(sub int) []hexInfo {
+	var worlds []hexInfo
+	for _, hex := range s.hexes {
+		if x, y, ok := parseLocationCode(hex.location); ok && subsectorOf(x, y) == sub {
+			worlds = append(worlds, hex)
+		}
+	}
+	return worlds
+}
+
+// routesIn lists the routes that start or end in a subsector, a line for
+// each type of route.
+func (s sector) routesIn(sub int) []string {
+	var types []string
+	byType := map[string][]string{}
+	for _, route := range s.routes {
+		x1, y1, ok1 := parseLocationCode(route.Start)
+		x2, y2, ok2 := parseLocationCode(route.End)
+		if !(ok1 && subsectorOf(x1, y1) == sub) && !(ok2 && subsectorOf(x2, y2) == sub) {
+			continue
+		}
+
+		kind := route.Type
+		if kind == "" {
+			kind = "Route"
+		}
+		if _, ok := byType[kind]; !ok {
+			types = append(types, kind)
+		}
+		byType[kind] = append(byType[kind], route.Start+"-"+route.End)
+	}
+
+	var lines []string
+	for _, kind := range types {
+		lines = append(lines, fmt.Sprintf("%-6s %s", kind, strings.Join(byType[kind], " ")))
+	}
+	return lines
+}
+
+// coloniesIn lists the owned worlds of a subsector, a line for each with
+// the world that owns it.
+func (s sector) coloniesIn(sub int) []string {
+	var lines []string
+	for _, hex := range s.subsector(sub) {
+		if owner, ok := s.owner(hex); ok {
+			lines = append(lines, fmt.Sprintf("%s %s owned by %s %s", hex.location, hex.name, owner.location, owner.name))
+		}
+	}
+	return lines
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// generateSector rolls a whole sector in the background.
+func generateSector(opts Options) tea.Cmd {
 	return func() tea.Msg {
-		planets := newPlanets()
-
-		var worlds []hexInfo
-
-		for hx := 1; hx <= 32; hx++ {
-			for hy := 1; hy <= 40; hy++ {
-				locationCode := getLocationCode(hx, hy)
-
-				if rollDecimal(1, 20) < 8 {
-					population := getPopulation()
-					starport := getStarportQuality(population)
-					size := worldSize(dice(2) - 2)
-					atmosphere := getAtmosphere(size)
-					hydrographics := getHydrographics(size, atmosphere)
-					government := getGovernment(population)
-					law := getLawLevel(government)
-					tech := getTechLevel(starport, size, atmosphere, hydrographics, population, government)
-					uwp := strings.ToUpper(fmt.Sprintf("%s%x%x%x%x%x%x-%x", starport, size, atmosphere, hydrographics, population, government, law, tech))
-
-					bases := getBases(starport)
-
-					primary := getPrimary()
-					var stars string
-					if primary.size == "D" {
-						if primary.class.letter == "B" {
-							stars = "BD"
-						} else {
-							stars = "D"
-						}
-					} else {
-						stars = fmt.Sprintf("%s%d %s", primary.class.letter, primary.class.numeral, primary.size)
-					}
-					hzVar := getHzVar(primary)
-
-					hex := hexInfo{
-						name:     planets.Name(),
-						location: locationCode,
-						uwp:      uwp,
-						bases:    bases,
-						primary:  primary,
-						hzVar:    hzVar,
-						stars:    stars,
-					}
-
-					hex.zone = getZone(hex)
-					hex.remarks = getTradeCodes(hex)
-
-					hex.populationMultiplier = getPopulationMultiplier(hex)
-					hex.belts = applyMinimum(dice(1)-3, 0)
-					hex.gasGiants = applyMinimum(dice(2)/2-2, 0)
-
-					hex.allegiance = "Gc"
-					hex.importance = getImportanceExtension(hex)
-
-					hex.resources = getResources(hex)
-					hex.labor = getLabor(hex)
-					hex.infrastructure = getInfrastructure(hex)
-					hex.efficiencies = getEfficiencies(hex)
-
-					hex.heterogeneity = getHeterogeneity(hex)
-					hex.acceptance = getAcceptance(hex)
-					hex.strangeness = getStrangeness(hex)
-					hex.symbols = getSymbols(hex)
-
-					hex.nobility = getNobility(hex)
-
-					hex.worlds = getWorlds(hex)
-
-					worlds = append(worlds, hex)
-				}
+		return buildSector(opts)
+	}
+}
+
+// buildSector rolls a whole sector around any locked worlds, which take
+// their hexes as they are and then join in everything laid out across the
+// sector. The same seed always produces the same sector, as long as names
+// come from the offline generator.
+func buildSector(opts Options, locked ...hexInfo) sector {
+	r := newRoller(opts.Seed, opts.Rules)
+	planets := newPlanets(newNameSource(r, opts.Online))
+
+	var worlds []hexInfo
+	noise := newDensityNoise(r.rng)
+
+	for hx := 1; hx <= 32; hx++ {
+		for hy := 1; hy <= 40; hy++ {
+			locationCode := getLocationCode(hx, hy)
+			density, _ := densityChance(opts.Density[subsectorOf(hx, hy)])
+
+			if r.rng.Float64() < placementChance(density, noise.at(hx, hy)) {
+				worlds = append(worlds, r.generateWorld(locationCode, planets))
 			}
 		}
+	}
 
-		var sector = sector{
-			name:  planets.Name(),
-			hexes: worlds,
-		}
+	var sector = sector{
+		name:     opts.Name,
+		seed:     opts.Seed,
+		rules:    r.rules.name,
+		hexes:    worlds,
+		comments: []string{fmt.Sprintf(" Seed: %d", opts.Seed)},
+	}
 
-		return sector
+	for _, world := range locked {
+		sector = sector.withWorld(r.rederive(unsettled(world)))
 	}
+	sector = r.settle(sector, opts.sophonts())
+
+	if !opts.Density.isDefault() {
+		sector.comments = append(sector.comments, fmt.Sprintf(" Density: %s", opts.Density))
+	}
+	if r.rules.name != T5 {
+		sector.comments = append(sector.comments, fmt.Sprintf(" Rules: %s", r.rules.name))
+	}
+
+	if sector.name == "" {
+		sector.name = planets.Name()
+	}
+	sector.notice = planets.notice()
+
+	subsectorNames := newMarkovNames(r, trainingNames)
+	for i := 0; i < 16; i++ {
+		sector.subsectors = append(sector.subsectors, subsectorNames.word())
+	}
+
+	return sector
+}
+
+// settle lays out everything that spans the sector once its worlds are in
+// their hexes: capitals, polities, sophonts, colonies and routes.
+func (r *roller) settle(s sector, sophonts []Sophont) sector {
+	s = r.chooseCapitals(s)
+	s = r.formPolities(s)
+	s = r.settleSophonts(s, sophonts)
+	s = r.claimColonies(s)
+	return r.traceRoutes(s)
 }
 
-func getPopulationMultiplier(hex hexInfo) int {
+// generateWorld rolls the mainworld for a single hex.
+func (r *roller) generateWorld(locationCode string, planets *planetnames) hexInfo {
+	population := r.rules.population(r)
+	starport := r.rules.starport(r, population)
+	size := r.rules.size(r)
+	atmosphere := r.rules.atmosphere(r, size)
+	hydrographics := r.rules.hydrographics(r, size, atmosphere)
+	government := r.rules.government(r, population)
+	law := r.rules.law(r, population, government)
+	tech := r.rules.techLevel(r, starport, size, atmosphere, hydrographics, population, government)
+	profile := uwp.UWP{
+		Starport:      starport[0],
+		Size:          int(size),
+		Atmosphere:    int(atmosphere),
+		Hydrographics: int(hydrographics),
+		Population:    int(population),
+		Government:    int(government),
+		Law:           int(law),
+		TechLevel:     int(tech),
+	}
+
+	bases := r.rules.bases(r, starport, population, law)
+
+	primary, spectralFlux, sizeFlux := r.rollPrimary()
+	companions := r.getCompanions(spectralFlux, sizeFlux)
+	stars := formatStars(primary, companions)
+	hzVar := r.getHzVar(primary, companions...)
+
+	hex := hexInfo{
+		name:       planets.Name(),
+		location:   locationCode,
+		uwp:        profile.String(),
+		bases:      bases,
+		primary:    primary,
+		companions: companions,
+		hzVar:      hzVar,
+		stars:      stars,
+	}
+
+	hex.belts = applyMinimum(r.dice(1)-3, 0)
+	hex.gasGiants = applyMinimum(r.dice(2)/2-2, 0)
+	hex.worlds = r.getWorlds(hex)
+	hex.system.mainworld = r.rollMainworldOrbit()
+	hex.system = r.generateSystem(hex)
+
+	hex.zone = r.rules.zone(r, hex)
+	hex.remarks = r.getTradeCodes(hex)
+
+	hex.populationMultiplier = r.getPopulationMultiplier(hex)
+
+	hex.allegiance = "Gc"
+	hex.importance = getImportanceExtension(hex)
+
+	hex.resources = r.getResources(hex)
+	hex.labor = getLabor(hex)
+	hex.infrastructure = r.getInfrastructure(hex)
+	hex.efficiencies = r.getEfficiencies(hex)
+
+	hex.heterogeneity = r.getHeterogeneity(hex)
+	hex.acceptance = getAcceptance(hex)
+	hex.strangeness = r.getStrangeness(hex)
+	hex.symbols = r.getSymbols(hex)
+
+	hex.nobility = getNobility(hex)
+
+	return hex
+}
+
+func (r *roller) getPopulationMultiplier(hex hexInfo) int {
 	if getNumericUwpValue(hex.uwp, Pop) == 0 {
 		return 0
 	}
-	return rollDecimal(1, 9)
+	return r.rollDecimal(1, 9)
 }
 
 type baseLetter string
@@ -608,6 +1389,8 @@ const (
 	scientificBase            = "E"
 	diplomaticBase            = "P"
 	culturalBase              = "C"
+	highport                  = "H"
+	corsairBase               = "R" // C is taken by cultural bases
 )
 
 func hasBase(required baseLetter) requirement {
@@ -740,8 +1523,8 @@ func getImportanceExtension(hex hexInfo) int {
 	return value
 }
 
-func getResources(hex hexInfo) int {
-	resources := dice(2)
+func (r *roller) getResources(hex hexInfo) int {
+	resources := r.dice(2)
 	if getNumericUwpValue(hex.uwp, TL) > 7 {
 		resources += hex.gasGiants + hex.belts
 	}
@@ -752,7 +1535,7 @@ func getLabor(hex hexInfo) int {
 	return applyMinimum(getNumericUwpValue(hex.uwp, Pop)-1, 0)
 }
 
-func getInfrastructure(hex hexInfo) int {
+func (r *roller) getInfrastructure(hex hexInfo) int {
 	var infrastructure int
 
 	population := getNumericUwpValue(hex.uwp, Pop)
@@ -763,16 +1546,16 @@ func getInfrastructure(hex hexInfo) int {
 	case 1, 2, 3:
 		infrastructure = hex.importance
 	case 4, 5, 6:
-		infrastructure = dice(1) + hex.importance
+		infrastructure = r.dice(1) + hex.importance
 	default:
-		infrastructure = dice(2) + hex.importance
+		infrastructure = r.dice(2) + hex.importance
 	}
 
 	return applyRange(infrastructure, 0, 0xF)
 }
 
-func getEfficiencies(_ hexInfo) int {
-	value := flux()
+func (r *roller) getEfficiencies(_ hexInfo) int {
+	value := r.flux()
 	if value == 0 {
 		return 1
 	}
@@ -865,7 +1648,10 @@ var nobilityRequirements = []nobilityEntry{
 	{title: viscount, requires: hasTradeCode(preHighPop)},
 	{title: count, requires: includeTradeCodes(industrial, highPopulation)},
 	{title: duke, requires: and(isImportant(4), excludeTradeCodes(subsectorCapitol, capitol, sectorCapitol))},
-	{title: duke, requires: includeTradeCodes(capitol, subsectorCapitol)},
+	{title: grandDuke, requires: includeTradeCodes(capitol, subsectorCapitol, sectorCapitol)},
+	// The sector capital is the seat of its own subsector as well, so its
+	// archduke sits alongside that subsector's grand duke.
+	{title: archduke, requires: hasTradeCode(sectorCapitol)},
 }
 
 func getNobility(hex hexInfo) []nobleTitle {
@@ -891,38 +1677,30 @@ func formatZone(value zoneType) string {
 	return string(value)
 }
 
+// saveSector writes an opened sector back where it came from, and a
+// generated one to a tab delimited file named for the sector. The metadata
+// XML goes alongside it.
 func saveSector(sector sector) tea.Cmd {
 	return func() tea.Msg {
-		f, err := os.Create(sector.name)
+		path, format := sector.name, sectorfile.Tab
+		if sector.path != "" {
+			path, format = sector.path, sector.format
+		}
+
+		f, err := os.Create(path)
 		check(err)
 		defer f.Close()
 
-		_, headerErr := f.WriteString("Hex\tName\tUWP\tBases\tRemarks\tZone\tPBG\tAllegiance\tStars\t{Ix}\t(Ex)\t[Cx]\tNobility\tW\n")
-		check(headerErr)
-
-		for _, h := range sector.hexes {
-			_, worldErr := f.WriteString(
-				fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
-					h.location,
-					h.name,
-					h.uwp,
-					h.bases,
-					h.remarks,
-					formatZone(h.zone),
-					fmt.Sprintf("%d%d%d", h.populationMultiplier, h.belts, h.gasGiants),
-					h.allegiance,
-					h.stars,
-					fmt.Sprintf("{ %d }", h.importance),
-					strings.ToUpper(fmt.Sprintf("(%x%x%x%+d)", h.resources, h.labor, h.infrastructure, h.efficiencies)),
-					strings.ToUpper(fmt.Sprintf("[%x%x%x%x]", h.heterogeneity, h.acceptance, h.strangeness, h.symbols)),
-					strings.Join(strings.Split(strings.Trim(fmt.Sprintf("%v", h.nobility), "[]"), " "), ""),
-					h.worlds,
-				))
-			check(worldErr)
-		}
-
+		check(writeSector(f, sector, format))
 		f.Sync()
 
+		meta, err := os.Create(metadataPath(path))
+		check(err)
+		defer meta.Close()
+
+		check(sectorfile.WriteMetadata(meta, sector.metadata()))
+		meta.Sync()
+
 		return saveSuccessful{
 			filename: f.Name(),
 			worlds:   len(sector.hexes),
@@ -930,6 +1708,56 @@ func saveSector(sector sector) tea.Cmd {
 	}
 }
 
+// writeSector writes the sector in the given format, starting with its
+// comments, which record the seed a generated sector was rolled from.
+func writeSector(w io.Writer, sector sector, format sectorfile.Format) error {
+	f := sectorfile.File{
+		Comments: sector.comments,
+	}
+
+	for _, h := range sector.hexes {
+		f.Worlds = append(f.Worlds, toFileWorld(h))
+	}
+
+	return sectorfile.Write(w, f, format)
+}
+
+// toFileWorld lays out a hex the way T5 Second Survey data writes it.
+func toFileWorld(h hexInfo) sectorfile.World {
+	w := sectorfile.World{
+		Hex:        h.location,
+		Name:       h.name,
+		UWP:        h.uwp,
+		Remarks:    h.remarks,
+		Ix:         fmt.Sprintf("{ %d }", h.importance),
+		Ex:         fmt.Sprintf("(%s%s%s%+d)", uwp.Encode(h.resources), uwp.Encode(h.labor), uwp.Encode(h.infrastructure), h.efficiencies),
+		Cx:         fmt.Sprintf("[%s%s%s%s]", uwp.Encode(h.heterogeneity), uwp.Encode(h.acceptance), uwp.Encode(h.strangeness), uwp.Encode(h.symbols)),
+		Nobility:   formatNobility(h.nobility),
+		Bases:      h.bases,
+		Zone:       formatZone(h.zone),
+		PBG:        fmt.Sprintf("%s%s%s", uwp.Encode(h.populationMultiplier), uwp.Encode(h.belts), uwp.Encode(h.gasGiants)),
+		W:          strconv.Itoa(h.worlds),
+		Allegiance: h.allegiance,
+		Stars:      h.stars,
+	}
+
+	for column, value := range map[string]*string{"Ix": &w.Ix, "Ex": &w.Ex, "Cx": &w.Cx, "PBG": &w.PBG, "W": &w.W} {
+		if h.absent[column] {
+			*value = ""
+		}
+	}
+
+	return w
+}
+
+func formatNobility(titles []nobleTitle) string {
+	var nobility strings.Builder
+	for _, title := range titles {
+		nobility.WriteString(string(title))
+	}
+	return nobility.String()
+}
+
 type saveSuccessful struct {
 	filename string
 	worlds   int
@@ -939,6 +1767,19 @@ func getLocationCode(x int, y int) string {
 	return fmt.Sprintf("%04d", x*100+y)
 }
 
+// parseLocationCode reads a hex such as "0407" back into its column and row.
+func parseLocationCode(code string) (x int, y int, ok bool) {
+	if len(code) != 4 {
+		return 0, 0, false
+	}
+	x, errX := strconv.Atoi(code[:2])
+	y, errY := strconv.Atoi(code[2:])
+	if errX != nil || errY != nil || x < 1 || x > 32 || y < 1 || y > 40 {
+		return 0, 0, false
+	}
+	return x, y, true
+}
+
 type requirement func(h hexInfo) bool
 
 type uwpElementType int
@@ -954,16 +1795,6 @@ const (
 	TL = 8
 )
 
-func decodeHex(value string) int {
-	decoded, err := strconv.ParseInt(value, 16, 32)
-
-	if err != nil {
-		panic(err)
-	}
-
-	return int(decoded)
-}
-
 func includesValue(allowed string, actual string) bool {
 	for i := 0; i < len(allowed); i++ {
 		next := string(allowed[i])
@@ -1055,16 +1886,18 @@ var tradeCodes = []definition{
 	define("Tu", is(Siz, "6789"), is(Atm, "456789"), is(Hyd, "34567"), hasHzVariance(1)),
 	// Secondary
 	//
+	define(satellite, mainworldIs(farSatellite, closeSatellite)),
+	define(locked, mainworldIs(closeSatellite)),
 	define("Re", is(Pop, "01234"), is(Gov, "6"), is(Law, "045")),
 	// Political
 	//
 	define("Cy", is(Pop, "01234"), is(Gov, "6"), is(Law, "0123")),
 }
 
-func getTradeCodes(hex hexInfo) string {
+func (r *roller) getTradeCodes(hex hexInfo) string {
 	var codes []tradeCode
 
-	for _, def := range tradeCodes {
+	for _, def := range r.rules.tradeCodes {
 		match := true
 		for _, req := range def.require {
 			if !req(hex) {
@@ -1077,29 +1910,27 @@ func getTradeCodes(hex hexInfo) string {
 		}
 	}
 
-	if getNumericUwpValue(hex.uwp, Gov) == 6 {
-		if !includeTradeCodes(militaryRule, prisonExile, reserve)(hex) {
-			codes = append(codes, militaryRule)
-		}
+	if r.rules.extraCodes != nil {
+		codes = append(codes, r.rules.extraCodes(hex)...)
 	}
 
 	return strings.Trim(fmt.Sprintf("%v", codes), "[]")
 }
 
-func getBases(starport starportClass) string {
+func (r *roller) getBases(starport starportClass, _ populationType, _ lawLevel) string {
 	var naval, scout bool
 
 	switch starport {
 	case "A":
-		naval = dice(2) < 7
-		scout = dice(2) < 5
+		naval = r.dice(2) < 7
+		scout = r.dice(2) < 5
 	case "B":
-		naval = dice(2) < 6
-		scout = dice(2) < 6
+		naval = r.dice(2) < 6
+		scout = r.dice(2) < 6
 	case "C":
-		scout = dice(2) < 7
+		scout = r.dice(2) < 7
 	case "D":
-		scout = dice(2) < 8
+		scout = r.dice(2) < 8
 	}
 
 	result := ""
@@ -1115,24 +1946,24 @@ func getBases(starport starportClass) string {
 	return result
 }
 
-func getSize() worldSize {
-	roll := dice(2) - 2
+func (r *roller) getSize() worldSize {
+	roll := r.dice(2) - 2
 	if roll == 10 {
-		return worldSize(dice(1) + 9)
+		return worldSize(r.dice(1) + 9)
 	}
 	return worldSize(roll)
 }
 
-func getPopulation() populationType {
-	roll := dice(2) - 2
+func (r *roller) getPopulation() populationType {
+	roll := r.dice(2) - 2
 
 	if roll == 10 {
-		return populationType(dice(2) + 3)
+		return populationType(r.dice(2) + 3)
 	}
 	return populationType(roll)
 }
 
-func getStarportQuality(population populationType) starportClass {
+func (r *roller) getStarportQuality(population populationType) starportClass {
 	var dm int
 	switch {
 	case population == 8, population == 9:
@@ -1145,7 +1976,7 @@ func getStarportQuality(population populationType) starportClass {
 		dm = -2
 	}
 
-	roll := dice(2) + dm
+	roll := r.dice(2) + dm
 
 	switch {
 	case roll > 10:
@@ -1163,8 +1994,8 @@ func getStarportQuality(population populationType) starportClass {
 	}
 }
 
-func getAtmosphere(size worldSize) atmosphereType {
-	roll := flux() + int(size)
+func (r *roller) getAtmosphere(size worldSize) atmosphereType {
+	roll := r.flux() + int(size)
 
 	if size == worldSize_0 {
 		return atmosphere_0
@@ -1173,7 +2004,7 @@ func getAtmosphere(size worldSize) atmosphereType {
 	return atmosphereType(applyRange(roll, 0, 0xF))
 }
 
-func getSurfaceTemp(atmosphere atmosphereType) int {
+func (r *roller) getSurfaceTemp(atmosphere atmosphereType) int {
 	var dm int
 
 	switch atmosphere {
@@ -1189,7 +2020,7 @@ func getSurfaceTemp(atmosphere atmosphereType) int {
 		dm = 6
 	}
 
-	habitableZoneLocation := dice(2)
+	habitableZoneLocation := r.dice(2)
 
 	switch {
 	case habitableZoneLocation > 9:
@@ -1198,10 +2029,10 @@ func getSurfaceTemp(atmosphere atmosphereType) int {
 		dm -= 4
 	}
 
-	return dice(2) + dm
+	return r.dice(2) + dm
 }
 
-func getHydrographics(size worldSize, atmosphere atmosphereType) hydrographicType {
+func (r *roller) getHydrographics(size worldSize, atmosphere atmosphereType) hydrographicType {
 	if size < 2 {
 		return 0
 	}
@@ -1213,24 +2044,24 @@ func getHydrographics(size worldSize, atmosphere atmosphereType) hydrographicTyp
 		dm -= 4
 	}
 
-	roll := flux() + int(atmosphere) + dm
+	roll := r.flux() + int(atmosphere) + dm
 
 	return hydrographicType(applyRange(roll, 0, 0xA))
 }
 
-func getGovernment(population populationType) governmentType {
-	result := flux() + int(population)
+func (r *roller) getGovernment(population populationType) governmentType {
+	result := r.flux() + int(population)
 
 	return governmentType(applyRange(result, 0, int(government_F)))
 }
 
-func getLawLevel(government governmentType) lawLevel {
-	result := flux() + int(government)
+func (r *roller) getLawLevel(_ populationType, government governmentType) lawLevel {
+	result := r.flux() + int(government)
 
 	return lawLevel(applyRange(result, 0, 0xF))
 }
 
-func getTechLevel(starport starportClass, size worldSize, atmosphere atmosphereType, hydrographics hydrographicType, population populationType, government governmentType) techLevel {
+func (r *roller) getTechLevel(starport starportClass, size worldSize, atmosphere atmosphereType, hydrographics hydrographicType, population populationType, government governmentType) techLevel {
 	var dm int
 
 	switch starport {
@@ -1259,14 +2090,14 @@ func getTechLevel(starport starportClass, size worldSize, atmosphere atmosphereT
 	}
 
 	switch hydrographics {
-	case 9:
+	case 0, 9:
 		dm += 1
 	case 0xA:
 		dm += 2
 	}
 
 	switch population {
-	case 1, 2, 3, 4, 5:
+	case 1, 2, 3, 4, 5, 8:
 		dm += 1
 	case 9:
 		dm += 2
@@ -1279,11 +2110,11 @@ func getTechLevel(starport starportClass, size worldSize, atmosphere atmosphereT
 		dm += 1
 	case 7:
 		dm += 2
-	case 0xD:
+	case 0xD, 0xE:
 		dm -= 2
 	}
 
-	result := dice(1) + dm
+	result := r.dice(1) + dm
 
 	environmentalMin := 0
 	switch atmosphere {
@@ -1339,8 +2170,8 @@ type cointoss struct {
 	remaining int
 }
 
-func newCoin() *cointoss {
-	return &cointoss{src: rand.NewSource(time.Now().UnixNano())}
+func newCoin(src rand.Source) *cointoss {
+	return &cointoss{src: src}
 }
 
 func (c *cointoss) Toss() bool {
@@ -1355,75 +2186,14 @@ func (c *cointoss) Toss() bool {
 	return result
 }
 
-func dice(d int) int {
+func (r *roller) dice(d int) int {
 	var result int
 	for i := 0; i < d; i++ {
-		result += rand.Intn(6) + 1
+		result += r.rng.Intn(6) + 1
 	}
 	return result
 }
 
-func flux() int {
-	return dice(1) - dice(1)
-}
-
-type planetnames struct {
-	names     []string
-	remaining int
-	current   string
-}
-
-func newPlanets() *planetnames {
-	return &planetnames{}
-}
-
-var usedNames = map[string]int{}
-
-func (p *planetnames) Name() string {
-	p.Next()
-	_, exists := usedNames[p.current]
-	if exists {
-		return p.Name()
-	} else {
-		usedNames[p.current] = 1
-		return p.current
-	}
-}
-
-func (p *planetnames) Next() {
-	if p.remaining == 0 {
-		p.names = getMorePlanetNames()
-		p.remaining = len(p.names)
-	}
-
-	result := p.names[0]
-	p.names = p.names[1:]
-	p.remaining--
-	p.current = result
-}
-
-func getMorePlanetNames() []string {
-	res, err := http.Get("https://donjon.bin.sh/name/rpc-name.fcgi?type=SciFi+World&n=10&as_json=1")
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	defer res.Body.Close()
-	if res.StatusCode != 200 {
-		log.Fatalf("status code error: %d %s", res.StatusCode, res.Status)
-	}
-
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	var names []string
-	json.Unmarshal(body, &names)
-
-	if len(names) < 1 {
-		log.Fatal("No names could be scraped")
-	}
-
-	return names
+func (r *roller) flux() int {
+	return r.dice(1) - r.dice(1)
 }
@@ -1,13 +1,23 @@
 package sector
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+
+	"github.com/charmbracelet/bubbles/help"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/violetexistence/traveller/generator/sectorfile"
 )
 
 func TestGetGovernment(t *testing.T) {
+	r := newRoller(1, T5)
 	for i := population_0; i <= population_A; i++ {
 		for j := 0; j < 1000; j++ {
-			actual := getGovernment(i)
+			actual := r.getGovernment(i)
 			if actual < government_0 || actual > government_D {
 				t.Fatalf("getGovernment(%d): %d?? Min: %d, Max: %d", i, actual, government_0, government_D)
 			}
@@ -35,8 +45,9 @@ func TestGetHzVar(t *testing.T) {
 		2:  0,
 	}
 
+	r := newRoller(1, T5)
 	for i := 0; i < 500; i++ {
-		actual := getHzVar(star)
+		actual := r.getHzVar(star)
 		switch actual {
 		case -2, -1, 0, 1, 2:
 			results[actual] = results[actual] + 1
@@ -59,3 +70,111 @@ func TestGetImportance(t *testing.T) {
 		t.Fatalf("Wrong! expected %d to be %d", actual, -1)
 	}
 }
+
+func TestNewRoller(t *testing.T) {
+	roll := func(r *roller) []int {
+		var rolls []int
+		for i := 0; i < 100; i++ {
+			rolls = append(rolls, r.dice(2), r.flux(), r.rollDecimal(1, 20))
+			if r.coin.Toss() {
+				rolls = append(rolls, 1)
+			}
+		}
+		return rolls
+	}
+
+	first := roll(newRoller(1138, T5))
+	second := roll(newRoller(1138, T5))
+
+	if len(first) != len(second) {
+		t.Fatalf("expected %d rolls, got %d", len(first), len(second))
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("roll %d differs: %d != %d", i, first[i], second[i])
+		}
+	}
+}
+
+func TestGenerateIsReproducible(t *testing.T) {
+	var first, second bytes.Buffer
+
+	if err := Generate(Output{Data: &first}, Options{Seed: 1977}, sectorfile.Tab); err != nil {
+		t.Fatal(err)
+	}
+	if err := Generate(Output{Data: &second}, Options{Seed: 1977}, sectorfile.Tab); err != nil {
+		t.Fatal(err)
+	}
+
+	if first.String() != second.String() {
+		t.Fatal("the same seed produced two different sectors")
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	m := buildSector(Options{Seed: 2112}).metadata()
+
+	if len(m.Subsectors) != 16 || m.Subsectors[15].Index != "P" {
+		t.Fatalf("expected subsectors A to P, got %+v", m.Subsectors)
+	}
+
+	if len(m.Allegiances) < 2 || !slices.Contains(m.Allegiances, sectorfile.Allegiance{Code: "NaHu", Name: "Non-Aligned, Human-dominated"}) {
+		t.Fatalf("expected polities and independents, got %+v", m.Allegiances)
+	}
+	for _, a := range m.Allegiances {
+		if a.Name == a.Code {
+			t.Errorf("allegiance %s has no name", a.Code)
+		}
+	}
+}
+
+func TestLoadMetadataSkipsUnindexedSubsectors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spinward.xml")
+	metadata := `<Sector>
+	<Name>Spinward Marches</Name>
+	<Subsector>Nowhere</Subsector>
+	<Subsector Index="B">Darrian</Subsector>
+</Sector>`
+	if err := os.WriteFile(path, []byte(metadata), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var s sector
+	if err := s.loadMetadata(path); err != nil {
+		t.Fatal(err)
+	}
+	if s.subsectors[0] != "" || s.subsectors[1] != "Darrian" {
+		t.Fatalf("expected only subsector B to be named, got %q", s.subsectors)
+	}
+}
+
+func TestSubsectorPages(t *testing.T) {
+	s := buildSector(Options{Seed: 2112})
+
+	total := 0
+	for sub := 0; sub < 16; sub++ {
+		for _, hex := range s.subsector(sub) {
+			x, y, _ := parseLocationCode(hex.location)
+			if (x-1)/8 != sub%4 || (y-1)/10 != sub/4 {
+				t.Fatalf("hex %s is not in subsector %c", hex.location, 'A'+sub)
+			}
+		}
+		total += len(s.subsector(sub))
+	}
+	if total != len(s.hexes) {
+		t.Fatalf("the pages hold %d of %d worlds", total, len(s.hexes))
+	}
+
+	var m tea.Model = model{sector: s, help: help.New()}
+	for i := 0; i < 20; i++ {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	}
+	if view := m.View(); !strings.Contains(view, "Subsector P: "+s.subsectors[15]) {
+		t.Fatalf("expected to page through to subsector P:\n%s", view)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	if view := m.View(); !strings.Contains(view, "3240") || strings.Contains(view, "0101") {
+		t.Fatalf("expected a map of subsector P:\n%s", view)
+	}
+}
@@ -0,0 +1,118 @@
+package sector
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/violetexistence/traveller/generator/sectorfile"
+)
+
+// allegianceNames are the polities our generated sectors know by name.
+var allegianceNames = map[string]string{
+	"Gc": "The Galactic Commons",
+	"Ic": "Independent Colony",
+	"Na": "Non-Aligned",
+}
+
+// metadataPath is where the metadata XML for a sector file lives.
+func metadataPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".xml"
+}
+
+// metadata describes the sector for Traveller Map: its subsectors, the
+// allegiances its worlds actually use, and its routes and borders.
+func (s sector) metadata() sectorfile.Metadata {
+	m := sectorfile.Metadata{
+		Name:       s.name,
+		Routes:     s.routes,
+		Borders:    s.borders,
+		Stylesheet: s.stylesheet,
+	}
+
+	for i, name := range s.subsectors {
+		if name != "" {
+			m.Subsectors = append(m.Subsectors, sectorfile.Subsector{
+				Index: sectorfile.SubsectorIndex(i),
+				Name:  name,
+			})
+		}
+	}
+
+	used := map[string]bool{}
+	for _, hex := range s.hexes {
+		if hex.allegiance != "" {
+			used[hex.allegiance] = true
+		}
+	}
+
+	var codes []string
+	for code := range used {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		m.Allegiances = append(m.Allegiances, sectorfile.Allegiance{
+			Code: code,
+			Name: s.allegianceName(code),
+		})
+	}
+
+	return m
+}
+
+func (s sector) allegianceName(code string) string {
+	if name, ok := s.allegiances[code]; ok {
+		return name
+	}
+	if name, ok := allegianceNames[code]; ok {
+		return name
+	}
+	return code
+}
+
+// loadMetadata reads the metadata saved alongside an opened sector, if any.
+func (s *sector) loadMetadata(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	m, err := sectorfile.ReadMetadata(f)
+	if err != nil {
+		return err
+	}
+
+	if m.Name != "" {
+		s.name = m.Name
+	}
+
+	s.subsectors = make([]string, 16)
+	for _, sub := range m.Subsectors {
+		if len(sub.Index) != 1 {
+			continue
+		}
+		if i := int(sub.Index[0] - 'A'); i >= 0 && i < 16 {
+			s.subsectors[i] = strings.TrimSpace(sub.Name)
+		}
+	}
+
+	s.allegiances = map[string]string{}
+	for _, a := range m.Allegiances {
+		s.allegiances[a.Code] = strings.TrimSpace(a.Name)
+	}
+
+	s.routes = m.Routes
+	s.borders = m.Borders
+	s.stylesheet = m.Stylesheet
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package sector
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// nameSource supplies batches of candidate planet names.
+type nameSource interface {
+	Names() ([]string, error)
+}
+
+// planetnames hands out each name once. Its source always ends in the
+// offline generator, which cannot fail, so there is always another name.
+type planetnames struct {
+	source    *fallbackNames
+	names     []string
+	remaining int
+	current   string
+	used      map[string]int
+}
+
+func newPlanets(source *fallbackNames) *planetnames {
+	return &planetnames{
+		source: source,
+		used:   map[string]int{},
+	}
+}
+
+// newNameSource returns the offline generator, backing donjon when online
+// names were asked for.
+func newNameSource(r *roller, online bool) *fallbackNames {
+	source := &fallbackNames{fallback: newMarkovNames(r, trainingNames)}
+	if online {
+		source.primary = donjonNames{}
+	}
+	return source
+}
+
+func (p *planetnames) Name() string {
+	p.Next()
+	_, exists := p.used[p.current]
+	if exists {
+		return p.Name()
+	} else {
+		p.used[p.current] = 1
+		return p.current
+	}
+}
+
+// notice says why offline names were used when online ones were asked for,
+// and is empty otherwise.
+func (p *planetnames) notice() string {
+	if p.source.err != nil {
+		return fmt.Sprintf("Using offline names: %v", p.source.err)
+	}
+	return ""
+}
+
+func (p *planetnames) Next() {
+	if p.remaining == 0 {
+		p.names = p.source.names()
+		p.remaining = len(p.names)
+	}
+
+	result := p.names[0]
+	p.names = p.names[1:]
+	p.remaining--
+	p.current = result
+}
+
+// fallbackNames asks the primary source first, if there is one, and
+// switches to the offline generator for good once the primary fails,
+// keeping the reason to report later.
+type fallbackNames struct {
+	primary  nameSource // nil for offline names only
+	fallback markovNames
+	err      error // why the primary failed, once it has
+}
+
+func (f *fallbackNames) names() []string {
+	if f.primary != nil && f.err == nil {
+		names, err := f.primary.Names()
+		if err == nil {
+			return names
+		}
+		f.err = err
+	}
+	return f.fallback.names()
+}
+
+// donjonClient gives up on donjon.bin.sh before a slow reply can hold up a
+// sector; the offline names take over instead.
+var donjonClient = &http.Client{Timeout: 10 * time.Second}
+
+// donjonNames scrapes science fiction world names from donjon.bin.sh.
+type donjonNames struct{}
+
+func (donjonNames) Names() ([]string, error) {
+	res, err := donjonClient.Get("https://donjon.bin.sh/name/rpc-name.fcgi?type=SciFi+World&n=10&as_json=1")
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("status code error: %d %s", res.StatusCode, res.Status)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	if err := json.Unmarshal(body, &names); err != nil {
+		return nil, err
+	}
+
+	if len(names) < 1 {
+		return nil, errors.New("no names could be scraped")
+	}
+
+	return names, nil
+}
+
+//go:embed names.txt
+var bundledNames string
+
+var trainingNames = strings.Fields(bundledNames)
+
+const markovOrder = 2
+
+// markovNames builds new names from a character level Markov chain trained
+// on the bundled word list. It rolls through the sector's roller, so names
+// follow the seed.
+type markovNames struct {
+	roll  *roller
+	chain map[string][]rune
+	min   int
+	max   int
+}
+
+func newMarkovNames(r *roller, words []string) markovNames {
+	chain := map[string][]rune{}
+
+	for _, word := range words {
+		padded := []rune(strings.Repeat("^", markovOrder) + strings.ToLower(word) + "$")
+		for i := markovOrder; i < len(padded); i++ {
+			prefix := string(padded[i-markovOrder : i])
+			chain[prefix] = append(chain[prefix], padded[i])
+		}
+	}
+
+	return markovNames{roll: r, chain: chain, min: 4, max: 10}
+}
+
+// names never fails, which is what lets it stand in for any other source.
+func (m markovNames) names() []string {
+	var names []string
+	for len(names) < 10 {
+		names = append(names, m.decorate(m.word()))
+	}
+	return names
+}
+
+func (m markovNames) word() string {
+	for {
+		prefix := strings.Repeat("^", markovOrder)
+		var word []rune
+
+		for len(word) <= m.max {
+			next := m.chain[prefix]
+			r := next[m.roll.rng.Intn(len(next))]
+			if r == '$' {
+				break
+			}
+			word = append(word, r)
+			prefix = string([]rune(prefix + string(r))[1:])
+		}
+
+		if len(word) >= m.min && len(word) <= m.max {
+			return strings.ToUpper(string(word[:1])) + string(word[1:])
+		}
+	}
+}
+
+var romanNumerals = []string{"II", "III", "IV", "V", "VI", "VII", "VIII", "IX"}
+
+// decorate dresses up some names the way charted space tends to: colonies
+// named for somewhere else, numbered planets and survey catalogue entries.
+func (m markovNames) decorate(name string) string {
+	switch roll := m.roll.dice(2); {
+	case roll == 2:
+		return fmt.Sprintf("%d %s", m.roll.rollDecimal(1000, 9999), name)
+	case roll == 3:
+		return "New " + name
+	case roll > 10:
+		return name + " " + romanNumerals[m.roll.rng.Intn(len(romanNumerals))]
+	case roll == 10:
+		return name + "'s World"
+	}
+	return name
+}
@@ -0,0 +1,45 @@
+package sector
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMarkovNames(t *testing.T) {
+	first := newMarkovNames(newRoller(7, T5), trainingNames).names()
+	second := newMarkovNames(newRoller(7, T5), trainingNames).names()
+
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("name %d differs: %q != %q", i, first[i], second[i])
+		}
+		if first[i] == "" {
+			t.Fatalf("name %d is empty", i)
+		}
+	}
+}
+
+type brokenNames struct{}
+
+func (brokenNames) Names() ([]string, error) {
+	return nil, errors.New("offline")
+}
+
+func TestFallbackNames(t *testing.T) {
+	planets := newPlanets(&fallbackNames{
+		primary:  brokenNames{},
+		fallback: newMarkovNames(newRoller(1, T5), trainingNames),
+	})
+
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		name := planets.Name()
+		if seen[name] {
+			t.Fatalf("%q was handed out twice", name)
+		}
+		seen[name] = true
+	}
+	if notice := planets.notice(); notice != "Using offline names: offline" {
+		t.Fatalf("expected the fallback noticed, got %q", notice)
+	}
+}
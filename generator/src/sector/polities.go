@@ -0,0 +1,340 @@
+package sector
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/violetexistence/traveller/generator/sectorfile"
+)
+
+const (
+	// polityJump is how far a polity reaches from one of its worlds to
+	// take in the next.
+	polityJump = 2
+	// politySpacing keeps the seats of two polities apart.
+	politySpacing = 12
+	// independent is the allegiance of a world no polity holds.
+	independent = "NaHu"
+)
+
+// polityKinds are the forms a polity takes, with the two letters that end
+// its allegiance code.
+var polityKinds = []struct{ name, code string }{
+	{"Federation", "Fd"},
+	{"Union", "Un"},
+	{"League", "Le"},
+	{"Confederacy", "Co"},
+	{"Assembly", "As"},
+	{"Republic", "Re"},
+	{"Hegemony", "He"},
+	{"Free Worlds", "Fw"},
+}
+
+// polity is an interstellar state grown out from the world it is named for.
+type polity struct {
+	seat  int // index of its capital in the sector's hexes
+	code  string
+	name  string
+	reach int // in jumps of polityJump
+}
+
+// formPolities carves the sector up between a few interstellar states. Each
+// is seated on one of the most important worlds, far enough from the
+// others, and spreads a jump at a time over the worlds it can reach. Worlds
+// just past a polity's reach become its client states, and the rest stay
+// independent. Each polity gets a border around the hexes it holds. Under
+// T5 rules the seat of each polity is marked Cx.
+func (r *roller) formPolities(s sector) sector {
+	s.hexes = slices.Clone(s.hexes)
+	s.allegiances = map[string]string{independent: "Non-Aligned, Human-dominated"}
+
+	polities := r.seatPolities(s)
+	for _, p := range polities {
+		s.allegiances[p.code] = p.name
+	}
+
+	holder := map[int]int{}
+	for i, p := range polities {
+		holder[p.seat] = i
+	}
+
+	frontier := map[int][]int{}
+	for i, p := range polities {
+		frontier[i] = []int{p.seat}
+	}
+	for jump := 1; len(frontier) > 0; jump++ {
+		for i, p := range polities {
+			if jump > p.reach {
+				delete(frontier, i)
+				continue
+			}
+			var next []int
+			for _, from := range frontier[i] {
+				for w := range s.hexes {
+					if _, held := holder[w]; !held && hexDistance(s.hexes[from].location, s.hexes[w].location) <= polityJump {
+						holder[w] = i
+						next = append(next, w)
+					}
+				}
+			}
+			frontier[i] = next
+		}
+	}
+
+	clients := map[int]int{}
+	for w := range s.hexes {
+		if _, held := holder[w]; held || getNumericUwpValue(s.hexes[w].uwp, Pop) == 0 {
+			continue
+		}
+		for held, i := range holder {
+			if hexDistance(s.hexes[held].location, s.hexes[w].location) <= polityJump {
+				if c, ok := clients[w]; !ok || i < c {
+					clients[w] = i
+				}
+			}
+		}
+	}
+
+	for w := range s.hexes {
+		if i, ok := holder[w]; ok {
+			s.hexes[w].allegiance = polities[i].code
+		} else if i, ok := clients[w]; ok {
+			code := "Cs" + polities[i].code[:2]
+			s.hexes[w].allegiance = code
+			s.allegiances[code] = "Client state, " + polities[i].name
+		} else {
+			s.hexes[w].allegiance = independent
+		}
+	}
+
+	if r.rules.capitals {
+		for _, p := range polities {
+			seat := s.hexes[p.seat]
+			// Cx goes ahead of Cp or Cs, so that choosing the capitals
+			// again leaves the remarks as they are.
+			remarks := strings.Fields(withoutRemarks(seat.remarks, capitol))
+			at := slices.IndexFunc(remarks, func(r string) bool {
+				return r == subsectorCapitol || r == sectorCapitol
+			})
+			if at < 0 {
+				at = len(remarks)
+			}
+			seat.remarks = strings.Join(slices.Insert(remarks, at, capitol), " ")
+			seat.nobility = getNobility(seat)
+			s.hexes[p.seat] = seat
+		}
+	}
+
+	var codes []string
+	for _, p := range polities {
+		codes = append(codes, p.code)
+	}
+	s.borders = polityBorders(s, codes)
+
+	return s
+}
+
+// polityBorders outlines the hexes held by each of the given allegiances.
+func polityBorders(s sector, codes []string) []sectorfile.Border {
+	var borders []sectorfile.Border
+	held := territory(s)
+	for _, code := range codes {
+		hexes := map[string]bool{}
+		for location, allegiance := range held {
+			if allegiance == code {
+				hexes[location] = true
+			}
+		}
+		for _, loop := range outline(hexes) {
+			borders = append(borders, sectorfile.NewBorder(code, loop))
+		}
+	}
+	return borders
+}
+
+// seatPolities picks the worlds polities grow from: the most important
+// populous worlds with good starports, no two close together.
+func (r *roller) seatPolities(s sector) []polity {
+	var candidates []int
+	for i, hex := range s.hexes {
+		if is(St, "AB")(hex) && getNumericUwpValue(hex.uwp, Pop) >= 6 {
+			candidates = append(candidates, i)
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b int) int {
+		switch {
+		case outranks(s.hexes[a], s.hexes[b]):
+			return -1
+		case outranks(s.hexes[b], s.hexes[a]):
+			return 1
+		}
+		return 0
+	})
+
+	count := r.rollDecimal(3, 6)
+	var polities []polity
+	used := map[string]bool{independent: true}
+	for _, c := range candidates {
+		if len(polities) == count {
+			break
+		}
+		if slices.ContainsFunc(polities, func(p polity) bool {
+			return hexDistance(s.hexes[p.seat].location, s.hexes[c].location) < politySpacing
+		}) {
+			continue
+		}
+
+		kind := polityKinds[r.rng.Intn(len(polityKinds))]
+		code := initials(s.hexes[c].name) + kind.code
+		if used[code] || used["Cs"+code[:2]] {
+			continue
+		}
+		used[code], used["Cs"+code[:2]] = true, true
+
+		polities = append(polities, polity{
+			seat:  c,
+			code:  code,
+			name:  s.hexes[c].name + " " + kind.name,
+			reach: 2 + applyMinimum(s.hexes[c].importance, 0) + r.rollDecimal(0, 2),
+		})
+	}
+	return polities
+}
+
+// initials are the first two letters of a name, as they start an
+// allegiance code: "Wezelia's World" is "We".
+func initials(name string) string {
+	var letters []rune
+	for _, r := range name {
+		if unicode.IsLetter(r) && r < unicode.MaxASCII {
+			letters = append(letters, unicode.ToLower(r))
+		}
+	}
+	for len(letters) < 2 {
+		letters = append(letters, 'x')
+	}
+	return string(unicode.ToUpper(letters[0])) + string(letters[1])
+}
+
+// territory is the allegiance that holds each hex: that of the world in
+// it, or else of the first world next to it.
+func territory(s sector) map[string]string {
+	held := map[string]string{}
+	for _, hex := range s.hexes {
+		held[hex.location] = hex.allegiance
+	}
+	for _, hex := range s.hexes {
+		at := toAxial(hex.location)
+		for dir := range neighbours {
+			if location, ok := at.step(dir).location(); ok {
+				if _, taken := held[location]; !taken {
+					held[location] = hex.allegiance
+				}
+			}
+		}
+	}
+	return held
+}
+
+// axial is a hex as column and diagonal row, so that every neighbour is the
+// same step away whichever column the hex is in.
+type axial struct{ q, r int }
+
+// neighbours step around a hex anticlockwise, as the map is drawn.
+var neighbours = [6]axial{{1, 0}, {1, -1}, {0, -1}, {-1, 0}, {-1, 1}, {0, 1}}
+
+func toAxial(location string) axial {
+	x, y, _ := parseLocationCode(location)
+	q := x - 1
+	return axial{q, y - (q-q&1)/2}
+}
+
+func (a axial) location() (string, bool) {
+	x, y := a.q+1, a.r+(a.q-a.q&1)/2
+	if x < 1 || x > 32 || y < 1 || y > 40 {
+		return "", false
+	}
+	return getLocationCode(x, y), true
+}
+
+func (a axial) step(d int) axial {
+	n := neighbours[(d%6+6)%6]
+	return axial{a.q + n.q, a.r + n.r}
+}
+
+// outline walks the edge of a set of hexes and lists, for each separate
+// piece, the hexes along its edge in order, back to the first, as a
+// <Border> path does. The edges of holes are left out.
+func outline(hexes map[string]bool) [][]string {
+	inside := func(a axial) bool {
+		location, ok := a.location()
+		return ok && hexes[location]
+	}
+
+	type edge struct {
+		hex axial
+		dir int
+	}
+	walked := map[edge]bool{}
+
+	var keys []string
+	for location := range hexes {
+		keys = append(keys, location)
+	}
+	slices.Sort(keys)
+
+	var loops [][]string
+	for _, location := range keys {
+		start := toAxial(location)
+		for dir := 0; dir < 6; dir++ {
+			e := edge{start, dir}
+			if walked[e] || inside(start.step(dir)) {
+				continue
+			}
+
+			// Follow the edge round, one hex edge at a time.
+			var path []string
+			var area float64
+			for !walked[e] {
+				walked[e] = true
+				at, _ := e.hex.location()
+				if len(path) == 0 || path[len(path)-1] != at {
+					path = append(path, at)
+				}
+
+				next := edge{e.hex, e.dir + 1}
+				if n := e.hex.step(e.dir + 1); inside(n) {
+					next = edge{n, e.dir - 1}
+				}
+				next.dir = (next.dir + 6) % 6
+
+				ax, ay := e.hex.midpoint(e.dir)
+				bx, by := next.hex.midpoint(next.dir)
+				area += ax*by - bx*ay
+				e = next
+			}
+
+			// Walking the outside of a piece turns the same way as walking
+			// round a lone hex; walking round a hole turns the other way.
+			if area < 0 {
+				if path[len(path)-1] != path[0] {
+					path = append(path, path[0])
+				}
+				loops = append(loops, path)
+			}
+		}
+	}
+	return loops
+}
+
+// midpoint is the middle of one edge of a hex, on a grid of unit hexes.
+func (a axial) midpoint(dir int) (float64, float64) {
+	centre := func(h axial) (float64, float64) {
+		return 1.5 * float64(h.q), math.Sqrt(3) * (float64(h.r) + float64(h.q)/2)
+	}
+	x1, y1 := centre(a)
+	x2, y2 := centre(a.step(dir))
+	return (x1 + x2) / 2, (y1 + y2) / 2
+}
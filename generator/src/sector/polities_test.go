@@ -0,0 +1,97 @@
+package sector
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/violetexistence/traveller/generator/sectorfile"
+)
+
+func TestOutline(t *testing.T) {
+	set := func(hexes ...string) map[string]bool {
+		m := map[string]bool{}
+		for _, h := range hexes {
+			m[h] = true
+		}
+		return m
+	}
+
+	if loops := outline(set("0505")); len(loops) != 1 || strings.Join(loops[0], " ") != "0505" {
+		t.Errorf("expected a lone hex outlined by itself, got %v", loops)
+	}
+
+	if loops := outline(set("0505", "0506", "0507")); len(loops) != 1 || strings.Join(loops[0], " ") != "0505 0506 0507 0506 0505" {
+		t.Errorf("expected a line walked out and back, got %v", loops)
+	}
+
+	if loops := outline(set("0101", "0909")); len(loops) != 2 {
+		t.Errorf("expected two pieces, got %v", loops)
+	}
+
+	// A ring of hexes has an outside edge and an inside one round the hole.
+	ring := set()
+	for dir := range neighbours {
+		location, _ := toAxial("0505").step(dir).location()
+		ring[location] = true
+	}
+	loops := outline(ring)
+	if len(loops) != 1 || len(loops[0]) != 7 || loops[0][0] != loops[0][6] {
+		t.Fatalf("expected the outside of the ring only, got %v", loops)
+	}
+}
+
+func TestFormPolities(t *testing.T) {
+	s := buildSector(Options{Seed: 2112})
+
+	seats := map[string]hexInfo{}
+	for _, hex := range s.hexes {
+		if _, ok := s.allegiances[hex.allegiance]; !ok {
+			t.Fatalf("%s %s has allegiance %q with no name", hex.location, hex.name, hex.allegiance)
+		}
+		if hasTradeCode(capitol)(hex) {
+			seats[hex.allegiance] = hex
+		}
+	}
+	if len(seats) < 3 {
+		t.Fatalf("expected at least three polities, got %d", len(seats))
+	}
+
+	for code, seat := range seats {
+		if !strings.HasPrefix(s.allegiances[code], seat.name+" ") {
+			t.Errorf("polity %s is not named for its seat %s", s.allegiances[code], seat.name)
+		}
+		if !slices.ContainsFunc(s.borders, func(b sectorfile.Border) bool { return b.Allegiance == code }) {
+			t.Errorf("polity %s has no border", code)
+		}
+
+		// Every world of the polity is reached from its seat a jump at a
+		// time without leaving it.
+		reached := map[string]bool{seat.location: true}
+		for queue := []string{seat.location}; len(queue) > 0; queue = queue[1:] {
+			for _, hex := range s.hexes {
+				if hex.allegiance == code && !reached[hex.location] && hexDistance(queue[0], hex.location) <= polityJump {
+					reached[hex.location] = true
+					queue = append(queue, hex.location)
+				}
+			}
+		}
+		for _, hex := range s.hexes {
+			if hex.allegiance == code && !reached[hex.location] {
+				t.Errorf("%s %s is cut off from the rest of %s", hex.location, hex.name, code)
+			}
+		}
+	}
+
+	for _, hex := range s.hexes {
+		if !strings.HasPrefix(hex.allegiance, "Cs") {
+			continue
+		}
+		patron := hex.allegiance[2:]
+		if !slices.ContainsFunc(s.hexes, func(h hexInfo) bool {
+			return strings.HasPrefix(h.allegiance, patron) && hexDistance(h.location, hex.location) <= polityJump
+		}) {
+			t.Errorf("client state %s %s is out of reach of its patron", hex.location, hex.name)
+		}
+	}
+}
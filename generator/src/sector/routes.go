@@ -0,0 +1,220 @@
+package sector
+
+import (
+	"math"
+	"slices"
+
+	"github.com/violetexistence/traveller/generator/sectorfile"
+)
+
+// Route types, as Traveller Map styles them.
+const (
+	tradeRoute = "Trade"
+	xboatRoute = "Xboat"
+)
+
+const (
+	// tradeJump is the longest jump a trade route makes in one go.
+	tradeJump = 4
+	// xboatJump is how far an express boat jumps between waystations.
+	xboatJump = 4
+	// minimumBTN is the smallest bilateral trade number worth a route.
+	minimumBTN = 10
+)
+
+// portModifiers adjust the world trade number for the starport, by the
+// unmodified number from 0 to 7 or more, after GURPS Far Trader. Columns
+// are A, B, C, D, E and X, as numbered by portColumns.
+var portModifiers = [8][6]float64{
+	{1.5, 1.5, 1, 1, 0.5, -5},
+	{1.5, 1, 1, 0.5, 0.5, -5},
+	{1, 1, 0.5, 0.5, 0, -5},
+	{1, 0.5, 0.5, 0, 0, -5},
+	{0.5, 0.5, 0, 0, -0.5, -5},
+	{0.5, 0, 0, -0.5, -1, -5},
+	{0, 0, -0.5, -1, -1.5, -5},
+	{0, -0.5, -1, -1.5, -2, -5},
+}
+
+// portColumns number the starports for portModifiers. Spaceports trade
+// like the starports of the same quality.
+var portColumns = map[byte]int{
+	'A': 0, 'B': 1, 'C': 2, 'D': 3, 'E': 4, 'X': 5,
+	'F': 2, 'G': 3, 'H': 4, 'Y': 5,
+}
+
+// worldTradeNumber is the World Trade Number: half the population, adjusted
+// for technology and then for how well the starport can move the goods.
+func worldTradeNumber(hex hexInfo) float64 {
+	wtn := float64(applyMinimum(getNumericUwpValue(hex.uwp, Pop), 0)) / 2
+
+	switch tech := getNumericUwpValue(hex.uwp, TL); {
+	case tech <= 1:
+		wtn -= 0.5
+	case tech <= 5:
+	case tech <= 8:
+		wtn += 0.5
+	case tech <= 11:
+		wtn += 1
+	default:
+		wtn += 1.5
+	}
+
+	port, ok := portColumns[hex.uwp[St]]
+	if !ok {
+		port = portColumns['X']
+	}
+	wtn += portModifiers[applyRange(int(wtn), 0, 7)][port]
+
+	return math.Max(wtn, 0)
+}
+
+// bilateralTradeNumber is the Bilateral Trade Number of two worlds a given
+// number of jumps apart: both WTNs, more for goods one has and the other
+// wants, less for distance and for crossing a border. Neither world can
+// trade more than five past what the smaller of them produces.
+func bilateralTradeNumber(a hexInfo, b hexInfo, jumps int) float64 {
+	wa, wb := worldTradeNumber(a), worldTradeNumber(b)
+	btn := wa + wb
+
+	complements := func(x, y tradeCode) bool {
+		return hasTradeCode(x)(a) && hasTradeCode(y)(b) || hasTradeCode(y)(a) && hasTradeCode(x)(b)
+	}
+	if complements(agricultural, nonAg) {
+		btn += 0.5
+	}
+	if complements(industrial, nonIndustrial) {
+		btn += 0.5
+	}
+	if a.allegiance != b.allegiance {
+		btn -= 1
+	}
+
+	switch {
+	case jumps <= 1:
+	case jumps == 2:
+		btn -= 0.5
+	case jumps <= 5:
+		btn -= 1
+	case jumps <= 9:
+		btn -= 1.5
+	default:
+		btn -= 2
+	}
+
+	return math.Min(btn, math.Min(wa, wb)+5)
+}
+
+// hexDistance is the number of hexes between two sector hexes, counting
+// the even columns as sitting half a hex lower than the odd ones.
+func hexDistance(from string, to string) int {
+	a, b := toAxial(from), toAxial(to)
+	dq, dr := a.q-b.q, a.r-b.r
+	return max(abs(dq), abs(dr), abs(dq+dr))
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// traceRoutes lays down the routes of a generated sector. Trade routes join
+// every pair of worlds within jump 4 whose bilateral trade is worth it. The
+// Xboat network joins the capitals along the shortest chain of jump 4 hops,
+// and every world it stops at gains a waystation, which raises its
+// importance.
+func (r *roller) traceRoutes(s sector) sector {
+	s.hexes = slices.Clone(s.hexes)
+	s.routes = nil
+
+	for i, a := range s.hexes {
+		for _, b := range s.hexes[i+1:] {
+			jumps := hexDistance(a.location, b.location)
+			if jumps <= tradeJump && bilateralTradeNumber(a, b, jumps) >= minimumBTN {
+				s.routes = append(s.routes, sectorfile.Route{Start: a.location, End: b.location, Type: tradeRoute})
+			}
+		}
+	}
+
+	stops := map[int]bool{}
+	for _, leg := range spanCapitals(s) {
+		path := jumpPath(s, leg[0], leg[1], xboatJump)
+		for j := 1; j < len(path); j++ {
+			s.routes = append(s.routes, sectorfile.Route{Start: s.hexes[path[j-1]].location, End: s.hexes[path[j]].location, Type: xboatRoute})
+		}
+		for _, stop := range path {
+			stops[stop] = true
+		}
+	}
+
+	for i := range s.hexes {
+		if stops[i] && !hasBase(waystation)(s.hexes[i]) {
+			s.hexes[i].bases += waystation
+			s.hexes[i] = r.rederive(s.hexes[i])
+		}
+	}
+
+	return s
+}
+
+// spanCapitals pairs up the capitals so that they are all joined by the
+// shortest total distance, growing the network out from the sector capital.
+func spanCapitals(s sector) [][2]int {
+	var capitals []int
+	for i, hex := range s.hexes {
+		if hasTradeCode(sectorCapitol)(hex) {
+			capitals = append([]int{i}, capitals...)
+		} else if hasTradeCode(subsectorCapitol)(hex) {
+			capitals = append(capitals, i)
+		}
+	}
+	if len(capitals) == 0 {
+		return nil
+	}
+
+	joined := []int{capitals[0]}
+	waiting := capitals[1:]
+	var legs [][2]int
+	for len(waiting) > 0 {
+		best, from := 0, joined[0]
+		for w, c := range waiting {
+			for _, j := range joined {
+				if hexDistance(s.hexes[j].location, s.hexes[c].location) < hexDistance(s.hexes[from].location, s.hexes[waiting[best]].location) {
+					best, from = w, j
+				}
+			}
+		}
+		legs = append(legs, [2]int{from, waiting[best]})
+		joined = append(joined, waiting[best])
+		waiting = slices.Delete(waiting, best, best+1)
+	}
+	return legs
+}
+
+// jumpPath finds the fewest jumps from one world to another, stopping only
+// at worlds, or nothing when the gaps are too wide.
+func jumpPath(s sector, from int, to int, jump int) []int {
+	previous := map[int]int{from: from}
+	queue := []int{from}
+	for len(queue) > 0 {
+		at := queue[0]
+		queue = queue[1:]
+		if at == to {
+			path := []int{to}
+			for at != from {
+				at = previous[at]
+				path = append([]int{at}, path...)
+			}
+			return path
+		}
+		for next, hex := range s.hexes {
+			if _, seen := previous[next]; !seen && hexDistance(s.hexes[at].location, hex.location) <= jump {
+				previous[next] = at
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
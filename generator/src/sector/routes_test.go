@@ -0,0 +1,133 @@
+package sector
+
+import (
+	"testing"
+)
+
+func TestHexDistance(t *testing.T) {
+	for _, test := range []struct {
+		from, to string
+		expected int
+	}{
+		{"0101", "0101", 0},
+		{"0101", "0201", 1},
+		{"0102", "0201", 1},
+		{"0101", "0202", 2},
+		{"0201", "0302", 1},
+		{"0202", "0301", 2},
+		{"0101", "0105", 4},
+		{"0101", "0501", 4},
+		{"0101", "3240", 55},
+	} {
+		if d := hexDistance(test.from, test.to); d != test.expected {
+			t.Errorf("%s to %s: expected %d, got %d", test.from, test.to, test.expected, d)
+		}
+		if d := hexDistance(test.to, test.from); d != test.expected {
+			t.Errorf("%s to %s: expected %d, got %d", test.to, test.from, test.expected, d)
+		}
+	}
+}
+
+func TestTradeNumbers(t *testing.T) {
+	for _, test := range []struct {
+		uwp      string
+		expected float64
+	}{
+		{"A867A69-F", 6.5},
+		{"C544658-8", 4},
+		{"X100000-0", 0},
+		{"E000100-5", 1},
+	} {
+		if wtn := worldTradeNumber(hexInfo{uwp: test.uwp}); wtn != test.expected {
+			t.Errorf("%s: expected WTN %.1f, got %.1f", test.uwp, test.expected, wtn)
+		}
+	}
+
+	mill := hexInfo{uwp: "C544558-8", remarks: "In", allegiance: "Gc"}
+	farm := hexInfo{uwp: "C544558-8", remarks: "Ag Ni", allegiance: "Gc"}
+	if btn := bilateralTradeNumber(mill, farm, 2); btn != 7 {
+		t.Errorf("expected BTN 7, got %.1f", btn)
+	}
+	farm.allegiance = "Na"
+	if btn := bilateralTradeNumber(mill, farm, 2); btn != 6 {
+		t.Errorf("expected a border to cost 1, got %.1f", btn)
+	}
+
+	hub := hexInfo{uwp: "A867A69-F", remarks: "Hi In"}
+	if btn := bilateralTradeNumber(hub, farm, 1); btn != 8.5 {
+		t.Errorf("expected trade capped at 5 past the smaller WTN, got %.1f", btn)
+	}
+}
+
+func TestTraceRoutes(t *testing.T) {
+	s := buildSector(Options{Seed: 2112})
+
+	worlds := map[string]hexInfo{}
+	for _, hex := range s.hexes {
+		worlds[hex.location] = hex
+	}
+
+	xboat := map[string][]string{}
+	trade := 0
+	for _, route := range s.routes {
+		if d := hexDistance(route.Start, route.End); d > 4 || d == 0 {
+			t.Fatalf("route %s-%s is jump %d", route.Start, route.End, d)
+		}
+		switch route.Type {
+		case tradeRoute:
+			trade++
+		case xboatRoute:
+			xboat[route.Start] = append(xboat[route.Start], route.End)
+			xboat[route.End] = append(xboat[route.End], route.Start)
+			for _, stop := range []string{route.Start, route.End} {
+				if !hasBase(waystation)(worlds[stop]) {
+					t.Errorf("Xboat stop %s has no waystation", stop)
+				}
+			}
+		default:
+			t.Fatalf("route %s-%s has type %q", route.Start, route.End, route.Type)
+		}
+	}
+	if trade == 0 || len(xboat) == 0 {
+		t.Fatalf("expected trade and Xboat routes, got %d trade and %d stops", trade, len(xboat))
+	}
+
+	// Every capital reachable over the Xboat network from the sector capital
+	// by plain jump 4 hops must be reached by the Xboats.
+	var seat string
+	for _, hex := range s.hexes {
+		if hasTradeCode(sectorCapitol)(hex) {
+			seat = hex.location
+		}
+	}
+	reached := map[string]bool{seat: true}
+	for queue := []string{seat}; len(queue) > 0; queue = queue[1:] {
+		for _, next := range xboat[queue[0]] {
+			if !reached[next] {
+				reached[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	from := -1
+	for i, hex := range s.hexes {
+		if hex.location == seat {
+			from = i
+		}
+	}
+	for i, hex := range s.hexes {
+		if hasTradeCode(subsectorCapitol)(hex) && jumpPath(s, from, i, xboatJump) != nil && !reached[hex.location] {
+			t.Errorf("capital %s %s is off the Xboat network", hex.location, hex.name)
+		}
+	}
+
+	for _, hex := range s.hexes {
+		if hex.importance != getImportanceExtension(hex) {
+			t.Fatalf("%s Ix { %d } does not count its bases %q", hex.location, hex.importance, hex.bases)
+		}
+	}
+
+	if len(s.metadata().Routes) != len(s.routes) {
+		t.Fatal("expected the routes in the metadata")
+	}
+}
@@ -0,0 +1,353 @@
+package sector
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RuleSet names the edition whose world creation tables are used.
+type RuleSet string
+
+const (
+	Classic RuleSet = "classic"
+	MgT2    RuleSet = "mgt2"
+	T5      RuleSet = "t5"
+)
+
+// ParseRuleSet accepts classic, mgt2 or t5. Empty means T5.
+func ParseRuleSet(name string) (RuleSet, error) {
+	switch r := RuleSet(strings.ToLower(name)); r {
+	case "":
+		return T5, nil
+	case Classic, MgT2, T5:
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown rules %q: expected classic, mgt2 or t5", name)
+}
+
+// rules are the world creation tables of one edition. Everything else, the
+// T5 extensions, PBG, stars and nobility, is shared.
+type rules struct {
+	name          RuleSet
+	size          func(r *roller) worldSize
+	atmosphere    func(r *roller, size worldSize) atmosphereType
+	hydrographics func(r *roller, size worldSize, atmosphere atmosphereType) hydrographicType
+	population    func(r *roller) populationType
+	government    func(r *roller, population populationType) governmentType
+	law           func(r *roller, population populationType, government governmentType) lawLevel
+	starport      func(r *roller, population populationType) starportClass
+	techLevel     func(r *roller, starport starportClass, size worldSize, atmosphere atmosphereType, hydrographics hydrographicType, population populationType, government governmentType) techLevel
+	bases         func(r *roller, starport starportClass, population populationType, law lawLevel) string
+	tradeCodes    []definition
+	extraCodes    func(hex hexInfo) []tradeCode
+	zone          func(r *roller, hex hexInfo) zoneType
+	capitals      bool // remarks mark the subsector and sector capitals
+	sophonts      bool // remarks mark sophont homeworlds and populations
+	owners        bool // remarks name the owner of a colony
+}
+
+var t5Rules = rules{
+	name:          T5,
+	size:          (*roller).getSize,
+	atmosphere:    (*roller).getAtmosphere,
+	hydrographics: (*roller).getHydrographics,
+	population:    (*roller).getPopulation,
+	government:    (*roller).getGovernment,
+	law:           (*roller).getLawLevel,
+	starport:      (*roller).bookStarport,
+	techLevel:     (*roller).bookTechLevel,
+	bases:         (*roller).getBases,
+	tradeCodes:    tradeCodes,
+	extraCodes:    militaryRuleCodes,
+	zone:          (*roller).getZone,
+	capitals:      true,
+	sophonts:      true,
+	owners:        true,
+}
+
+var mgt2Rules = rules{
+	name:          MgT2,
+	size:          (*roller).bookSize,
+	atmosphere:    (*roller).getAtmosphere,
+	hydrographics: (*roller).mgt2Hydrographics,
+	population:    (*roller).bookPopulation,
+	government:    (*roller).mgt2Government,
+	law:           (*roller).mgt2LawLevel,
+	starport:      (*roller).getStarportQuality,
+	techLevel:     (*roller).getTechLevel,
+	bases:         (*roller).mgt2Bases,
+	tradeCodes:    mgt2TradeCodes,
+	zone:          mgt2Zone,
+}
+
+var classicRules = rules{
+	name:          Classic,
+	size:          (*roller).bookSize,
+	atmosphere:    (*roller).getAtmosphere,
+	hydrographics: (*roller).classicHydrographics,
+	population:    (*roller).bookPopulation,
+	government:    (*roller).getGovernment,
+	law:           (*roller).getLawLevel,
+	starport:      (*roller).bookStarport,
+	techLevel:     (*roller).bookTechLevel,
+	bases:         basesOnRoll(map[starportClass]int{"A": 8, "B": 8}, map[starportClass]int{"A": 10, "B": 9, "C": 8, "D": 7}),
+	tradeCodes:    classicTradeCodes,
+	zone:          func(_ *roller, _ hexInfo) zoneType { return greenZone },
+}
+
+// rulesFor looks up the tables of an edition, T5 unless another is named.
+func rulesFor(set RuleSet) rules {
+	switch set {
+	case Classic:
+		return classicRules
+	case MgT2:
+		return mgt2Rules
+	}
+	return t5Rules
+}
+
+// bookSize is 2D-2, without the T5 roll up into the large sizes.
+func (r *roller) bookSize() worldSize {
+	return worldSize(r.dice(2) - 2)
+}
+
+func (r *roller) bookPopulation() populationType {
+	return populationType(r.dice(2) - 2)
+}
+
+// bookStarport is the flat 2D starport table of Classic Traveller and T5.
+func (r *roller) bookStarport(_ populationType) starportClass {
+	switch roll := r.dice(2); {
+	case roll < 5:
+		return "A"
+	case roll < 7:
+		return "B"
+	case roll < 9:
+		return "C"
+	case roll == 9:
+		return "D"
+	case roll < 12:
+		return "E"
+	}
+	return "X"
+}
+
+// bookTechLevel is the 1D tech level table shared by Classic Traveller and
+// T5, with no environmental minimum.
+func (r *roller) bookTechLevel(starport starportClass, size worldSize, atmosphere atmosphereType, hydrographics hydrographicType, population populationType, government governmentType) techLevel {
+	var dm int
+
+	switch starport {
+	case "A":
+		dm += 6
+	case "B":
+		dm += 4
+	case "C":
+		dm += 2
+	case "X":
+		dm -= 4
+	}
+
+	switch size {
+	case 0, 1:
+		dm += 2
+	case 2, 3, 4:
+		dm += 1
+	}
+
+	switch {
+	case atmosphere < 4, atmosphere > 9:
+		dm += 1
+	}
+
+	switch hydrographics {
+	case 9:
+		dm += 1
+	case 0xA:
+		dm += 2
+	}
+
+	switch {
+	case population > 0 && population < 6:
+		dm += 1
+	case population == 9:
+		dm += 2
+	case population > 9:
+		dm += 4
+	}
+
+	switch government {
+	case 0, 5:
+		dm += 1
+	case 0xD:
+		dm -= 2
+	}
+
+	return techLevel(applyRange(r.dice(1)+dm, 0, 15))
+}
+
+// classicHydrographics is 2D-7 plus size.
+func (r *roller) classicHydrographics(size worldSize, atmosphere atmosphereType) hydrographicType {
+	if size < 2 {
+		return 0
+	}
+
+	dm := 0
+	if atmosphere < 2 || atmosphere > 9 {
+		dm -= 4
+	}
+
+	return hydrographicType(applyRange(r.dice(2)-7+int(size)+dm, 0, 0xA))
+}
+
+// mgt2Hydrographics is 2D-7 plus atmosphere. There is no temperature yet, so
+// the DMs for hot and boiling worlds are left out.
+func (r *roller) mgt2Hydrographics(size worldSize, atmosphere atmosphereType) hydrographicType {
+	if size < 2 {
+		return 0
+	}
+
+	dm := 0
+	if atmosphere < 2 || (atmosphere > 9 && atmosphere < 0xD) {
+		dm -= 4
+	}
+
+	return hydrographicType(applyRange(r.dice(2)-7+int(atmosphere)+dm, 0, 0xA))
+}
+
+// mgt2Government leaves an empty world without a government.
+func (r *roller) mgt2Government(population populationType) governmentType {
+	if population == 0 {
+		return 0
+	}
+	return r.getGovernment(population)
+}
+
+// mgt2LawLevel leaves an empty world without laws, as mgt2Government leaves
+// it without a government.
+func (r *roller) mgt2LawLevel(population populationType, government governmentType) lawLevel {
+	if population == 0 {
+		return 0
+	}
+	return r.getLawLevel(population, government)
+}
+
+// mgt2BaseTargets is the MgT2 bases table: what 2D must make for each base
+// at each class of starport.
+var mgt2BaseTargets = []struct {
+	base    baseLetter
+	targets map[starportClass]int
+}{
+	{highport, map[starportClass]int{"A": 6, "B": 8, "C": 10, "D": 12}},
+	{militaryBase, map[starportClass]int{"A": 8, "B": 8, "C": 10}},
+	{navalBase, map[starportClass]int{"A": 8, "B": 8}},
+	{scoutBase, map[starportClass]int{"A": 10, "B": 9, "C": 9, "D": 8}},
+	{corsairBase, map[starportClass]int{"D": 12, "E": 10, "X": 10}},
+}
+
+// mgt2Bases rolls for each base in the MgT2 table. Highports are likelier
+// around populous worlds and corsairs where the law is light.
+func (r *roller) mgt2Bases(starport starportClass, population populationType, law lawLevel) string {
+	result := ""
+	for _, b := range mgt2BaseTargets {
+		target, ok := b.targets[starport]
+		if !ok {
+			continue
+		}
+		dm := 0
+		switch {
+		case b.base == highport && population >= 9:
+			dm = 1
+		case b.base == highport && population <= 6:
+			dm = -1
+		case b.base == corsairBase && law == 0:
+			dm = 2
+		case b.base == corsairBase && law >= 2:
+			dm = -2
+		}
+		if r.dice(2)+dm >= target {
+			result += string(b.base)
+		}
+	}
+	return result
+}
+
+// basesOnRoll gives each base at a starport when 2D makes its target.
+func basesOnRoll(naval, scout map[starportClass]int) func(r *roller, starport starportClass, population populationType, law lawLevel) string {
+	return func(r *roller, starport starportClass, _ populationType, _ lawLevel) string {
+		result := ""
+		if target, ok := naval[starport]; ok && r.dice(2) >= target {
+			result += "N"
+		}
+		if target, ok := scout[starport]; ok && r.dice(2) >= target {
+			result += "S"
+		}
+		return result
+	}
+}
+
+// militaryRuleCodes marks a captive government as under military rule,
+// unless it is already a prison, a reserve or the colony of an owner.
+func militaryRuleCodes(hex hexInfo) []tradeCode {
+	if getNumericUwpValue(hex.uwp, Gov) == 6 && !includeTradeCodes(militaryRule, prisonExile, reserve)(hex) && !hasOwner(hex) {
+		return []tradeCode{militaryRule}
+	}
+	return nil
+}
+
+// hasOwner is true of a colony whose remarks name its owner, as in O:1108.
+func hasOwner(hex hexInfo) bool {
+	for _, remark := range strings.Fields(hex.remarks) {
+		if strings.HasPrefix(remark, "O:") {
+			return true
+		}
+	}
+	return false
+}
+
+// mgt2Zone suggests an amber zone for the worlds the book calls out; red
+// zones are left to the referee.
+func mgt2Zone(_ *roller, hex hexInfo) zoneType {
+	switch {
+	case is(Atm, "ABCDEF")(hex),
+		is(Gov, "07A")(hex),
+		is(Law, "09ABCDEF")(hex):
+		return amberZone
+	}
+	return greenZone
+}
+
+var mgt2TradeCodes = []definition{
+	define(agricultural, is(Atm, "456789"), is(Hyd, "45678"), is(Pop, "567")),
+	define(asteroid, is(Siz, "0"), is(Atm, "0"), is(Hyd, "0")),
+	define(barren, is(Pop, "0"), is(Gov, "0"), is(Law, "0")),
+	define(desert, is(Atm, "23456789"), is(Hyd, "0")),
+	define(fluid, is(Atm, "ABCDEF"), is(Hyd, "123456789A")),
+	define(garden, is(Siz, "678"), is(Atm, "568"), is(Hyd, "567")),
+	define(highPopulation, is(Pop, "9ABCDEF")),
+	define(highTech, is(TL, "CDEF")),
+	define(iceCapped, is(Atm, "01"), is(Hyd, "123456789A")),
+	define(industrial, is(Atm, "012479ABC"), is(Pop, "9ABCDEF")),
+	define(lowPop, is(Pop, "123")),
+	define(lowTech, is(Pop, "123456789ABCDEF"), is(TL, "012345")),
+	define(nonAg, is(Atm, "0123"), is(Hyd, "0123"), is(Pop, "6789ABCDEF")),
+	define(nonIndustrial, is(Pop, "456")),
+	define(poor, is(Atm, "2345"), is(Hyd, "0123")),
+	define(rich, is(Atm, "68"), is(Pop, "678"), is(Gov, "456789")),
+	define(vacuum, is(Atm, "0")),
+	define(water, is(Atm, "3456789DEF"), is(Hyd, "A")),
+}
+
+var classicTradeCodes = []definition{
+	define(agricultural, is(Atm, "456789"), is(Hyd, "45678"), is(Pop, "567")),
+	define(nonAg, is(Atm, "0123"), is(Hyd, "0123"), is(Pop, "6789A")),
+	define(industrial, is(Atm, "012479"), is(Pop, "9A")),
+	define(nonIndustrial, is(Pop, "0123456")),
+	define(rich, is(Atm, "68"), is(Pop, "678"), is(Gov, "456789")),
+	define(poor, is(Atm, "2345"), is(Hyd, "0123")),
+	define(water, is(Hyd, "A")),
+	define(desert, is(Atm, "23456789ABC"), is(Hyd, "0")),
+	define(vacuum, is(Atm, "0")),
+	define(asteroid, is(Siz, "0")),
+	define(iceCapped, is(Atm, "01"), is(Hyd, "123456789A")),
+}
@@ -0,0 +1,82 @@
+package sector
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRuleSet(t *testing.T) {
+	for name, expected := range map[string]RuleSet{"": T5, "MgT2": MgT2, "classic": Classic} {
+		if actual, err := ParseRuleSet(name); err != nil || actual != expected {
+			t.Errorf("%q: expected %s, got %q %v", name, expected, actual, err)
+		}
+	}
+	if _, err := ParseRuleSet("gurps"); err == nil {
+		t.Fatal("expected an error for unknown rules")
+	}
+}
+
+func TestMgT2Rules(t *testing.T) {
+	s := buildSector(Options{Seed: 66, Rules: MgT2})
+
+	offered := map[baseLetter]map[starportClass]int{}
+	for _, row := range mgt2BaseTargets {
+		offered[row.base] = row.targets
+	}
+	bases := map[baseLetter]int{}
+	allowed := map[tradeCode]bool{}
+	for _, def := range mgt2TradeCodes {
+		allowed[def.code] = true
+	}
+
+	for _, hex := range s.hexes {
+		if size := getNumericUwpValue(hex.uwp, Siz); size > 0xA {
+			t.Fatalf("%s %s: size %X is beyond 2D-2", hex.location, hex.name, size)
+		}
+		if is(Pop, "0")(hex) && !(is(Gov, "0")(hex) && is(Law, "0")(hex)) {
+			t.Fatalf("%s %s: empty world with a government or laws", hex.location, hex.name)
+		}
+		for _, b := range hex.bases {
+			base := baseLetter(b)
+			if _, ok := offered[base][starportClass(hex.uwp[St:St+1])]; !ok {
+				t.Fatalf("%s %s: no %s at a class %c starport", hex.location, hex.name, baseNames[base], hex.uwp[St])
+			}
+			bases[base]++
+		}
+		if is(Atm, "01")(hex) && getNumericUwpValue(hex.uwp, TL) < 8 {
+			t.Fatalf("%s %s: below the environmental minimum", hex.location, hex.name)
+		}
+		for _, code := range strings.Fields(hex.remarks) {
+			if !allowed[tradeCode(code)] {
+				t.Fatalf("%s %s: %s is not an MgT2 trade code", hex.location, hex.name, code)
+			}
+		}
+	}
+
+	for _, base := range []baseLetter{highport, militaryBase, navalBase, scoutBase, corsairBase} {
+		if bases[base] == 0 {
+			t.Errorf("expected some of every MgT2 base, but no %s", baseNames[base])
+		}
+	}
+
+	if s.comments[len(s.comments)-1] != " Rules: mgt2" {
+		t.Fatalf("rules not recorded: %q", s.comments)
+	}
+}
+
+func TestDefaultRulesUseTheT5BookTables(t *testing.T) {
+	// With no rules named, starports and tech levels come from the flat
+	// book tables, not the population and environment tables of MgT2.
+	rolled, book := newRoller(5, Options{}.Rules), newRoller(5, T5)
+	for i := 0; i < 200; i++ {
+		population := populationType(i % 11)
+		starport := rolled.rules.starport(rolled, population)
+		if expected := book.bookStarport(population); starport != expected {
+			t.Fatalf("roll %d: expected starport %s, got %s", i, expected, starport)
+		}
+		tech := rolled.rules.techLevel(rolled, starport, 8, 6, 7, population, 5)
+		if expected := book.bookTechLevel(starport, 8, 6, 7, population, 5); tech != expected {
+			t.Fatalf("roll %d: expected tech level %d, got %d", i, expected, tech)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package sector
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/violetexistence/traveller/shared/uwp"
+)
+
+// SophontKind says where a sophont comes from and how its worlds are marked.
+type SophontKind string
+
+const (
+	// Major sophonts have a homeworld in the sector, marked [Name].
+	Major SophontKind = "major"
+	// Minor sophonts have a homeworld in the sector, marked (Name).
+	Minor SophontKind = "minor"
+	// Extinct sophonts left only their homeworld, marked Di(Name).
+	Extinct SophontKind = "extinct"
+	// Enclave sophonts come from outside the sector and live in enclaves
+	// on the worlds that suit them.
+	Enclave SophontKind = "enclave"
+)
+
+// Sophont is an intelligent species and the worlds that suit it. Each of
+// Size, Atmosphere and Hydrographics lists the eHex digits it can live
+// with, or is empty for any.
+type Sophont struct {
+	Code          string // four letters for population remarks, such as "Luri"
+	Name          string // for homeworld remarks, such as "Luriani"
+	Kind          SophontKind
+	Size          string
+	Atmosphere    string
+	Hydrographics string
+}
+
+// defaultSophonts are the peoples of the Gateway region besides mixed
+// ancestry humans, who are everywhere and go unremarked.
+var defaultSophonts = []Sophont{
+	{Code: "Akee", Name: "Akeed", Kind: Minor, Size: "456789", Atmosphere: "456789", Hydrographics: "6789A"},
+	{Code: "Swan", Name: "Swanfeh", Kind: Minor, Size: "5678", Atmosphere: "5678", Hydrographics: "345678"},
+	{Code: "Luri", Name: "Luriani", Kind: Enclave, Atmosphere: "56789", Hydrographics: "89A"},
+	{Code: "Jonk", Name: "Jonkeereen", Kind: Enclave, Atmosphere: "456789", Hydrographics: "01"},
+	{Code: "Sydi", Name: "Sydites", Kind: Enclave, Atmosphere: "5678"},
+}
+
+func (opts Options) sophonts() []Sophont {
+	if opts.Sophonts == nil {
+		return defaultSophonts
+	}
+	return opts.Sophonts
+}
+
+const (
+	// colonyJump is how far a sophont settles from its homeworld.
+	colonyJump = 3
+	// colonyOdds is one in how many suitable worlds near a homeworld hold
+	// a colony of its sophont.
+	colonyOdds = 3
+	// enclaveOdds is one in how many suitable worlds hold an enclave of a
+	// sophont from outside the sector.
+	enclaveOdds = 12
+)
+
+// suits is whether a sophont could live on a world.
+func (sp Sophont) suits(hex hexInfo) bool {
+	allows := func(element uwpElementType, digits string) bool {
+		return digits == "" || is(element, digits)(hex)
+	}
+	return allows(Siz, sp.Size) && allows(Atm, sp.Atmosphere) && allows(Hyd, sp.Hydrographics)
+}
+
+// settleSophonts places each sophont's homeworld on a world that suits it,
+// then its colonies on suitable worlds nearby, or its enclaves across the
+// sector. Each world it lives on is remarked with the share of the
+// population it makes up, in tenths, W for all. T5 lists homeworlds as
+// (Major) and (Minor), with the sophont's name in place of the word; like
+// Traveller Map we bracket a major race's name and parenthesise a minor
+// one's. An independent world where a sophont is in the majority counts as
+// non-aligned and dominated by that sophont. Only T5 marks sophonts in the
+// remarks.
+func (r *roller) settleSophonts(s sector, sophonts []Sophont) sector {
+	if !r.rules.sophonts {
+		return s
+	}
+
+	s.hexes = slices.Clone(s.hexes)
+	if s.allegiances == nil {
+		s.allegiances = map[string]string{}
+	}
+	homeworlds := map[int]bool{}
+
+	remark := func(i int, text string) {
+		s.hexes[i].remarks = strings.TrimSpace(s.hexes[i].remarks + " " + text)
+	}
+	// shares are the tenths of each world's population already given to a
+	// sophont, so that no world is remarked past the whole of it.
+	shares := map[int]int{}
+	settle := func(i int, text string, share int) {
+		share = min(share, 10-shares[i])
+		if share <= 0 {
+			return
+		}
+		shares[i] += share
+		remark(i, text+shareDigit(share))
+	}
+
+	for _, sp := range sophonts {
+		var suitable []int
+		for i, hex := range s.hexes {
+			populated := getNumericUwpValue(hex.uwp, Pop) > 0
+			if sp.suits(hex) && !homeworlds[i] && (populated && shares[i] < 10 || sp.Kind == Extinct) {
+				suitable = append(suitable, i)
+			}
+		}
+		if len(suitable) == 0 {
+			continue
+		}
+
+		if sp.Kind == Enclave {
+			for _, i := range suitable {
+				if r.rng.Intn(enclaveOdds) == 0 {
+					settle(i, sp.Code, r.rollDecimal(1, 3))
+				}
+			}
+			continue
+		}
+
+		home := suitable[r.rng.Intn(len(suitable))]
+		homeworlds[home] = true
+
+		switch sp.Kind {
+		case Extinct:
+			remark(home, "Di("+sp.Name+")")
+			continue
+		case Major:
+			remark(home, "["+sp.Name+"]")
+			shares[home] = 10
+		default:
+			settle(home, "("+sp.Name+")", r.rollDecimal(5, 10))
+			if s.hexes[home].allegiance == independent {
+				code := "Na" + sp.Code[:2]
+				s.hexes[home].allegiance = code
+				s.allegiances[code] = "Non-Aligned, " + sp.Name + "-dominated"
+			}
+		}
+
+		for _, i := range suitable {
+			if i != home && hexDistance(s.hexes[home].location, s.hexes[i].location) <= colonyJump && r.rng.Intn(colonyOdds) == 0 {
+				settle(i, sp.Code, r.rollDecimal(1, 5))
+			}
+		}
+	}
+
+	return s
+}
+
+// sophontRemark is whether a remark is about a sophont rather than a trade
+// code: a homeworld, as in (Akeed)7, [Vargr] or Di(Droyne), or a share of
+// the population, as in Luri3 or SwanW.
+func sophontRemark(remark string) bool {
+	if strings.HasPrefix(remark, "(") || strings.HasPrefix(remark, "[") || strings.HasPrefix(remark, "Di(") {
+		return true
+	}
+	code, share := remark, ""
+	if len(remark) == 5 {
+		code, share = remark[:4], remark[4:]
+	}
+	return sophontCode(code) && (share == "" || share == "W" || share >= "0" && share <= "9")
+}
+
+// sophontCode is whether a code is written the way remarks write sophonts:
+// four letters, the first a capital, as in Luri.
+func sophontCode(code string) bool {
+	if len(code) != 4 {
+		return false
+	}
+	for i, c := range code {
+		if i == 0 && (c < 'A' || c > 'Z') || i > 0 && (c < 'a' || c > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// shareDigit writes a share of the population in tenths, leaving out the W
+// of a world the sophont has to itself.
+func shareDigit(tenths int) string {
+	if tenths >= 10 {
+		return ""
+	}
+	return uwp.Encode(tenths)
+}
+
+// ReadSophonts reads a list of sophonts, tab delimited with a header line
+// naming the columns: Code, Name, Kind, Size, Atmosphere and Hydrographics.
+// Blank lines and lines starting with # are skipped.
+//
+//	Code	Name	Kind	Size	Atmosphere	Hydrographics
+//	Akee	Akeed	minor	456789	456789	6789A
+func ReadSophonts(r io.Reader) ([]Sophont, error) {
+	var header []string
+	var sophonts []Sophont
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		cells := strings.Split(text, "\t")
+		if header == nil {
+			header = cells
+			continue
+		}
+
+		var sp Sophont
+		for i, column := range header {
+			value := ""
+			if i < len(cells) {
+				value = strings.TrimSpace(cells[i])
+			}
+			switch strings.TrimSpace(column) {
+			case "Code":
+				sp.Code = value
+			case "Name":
+				sp.Name = value
+			case "Kind":
+				sp.Kind = SophontKind(strings.ToLower(value))
+			case "Size":
+				sp.Size = strings.ToUpper(value)
+			case "Atmosphere":
+				sp.Atmosphere = strings.ToUpper(value)
+			case "Hydrographics":
+				sp.Hydrographics = strings.ToUpper(value)
+			default:
+				return nil, fmt.Errorf("line %d: unknown column %q", line, column)
+			}
+		}
+
+		if err := sp.validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		sophonts = append(sophonts, sp)
+	}
+
+	return sophonts, scanner.Err()
+}
+
+func (sp Sophont) validate() error {
+	if !sophontCode(sp.Code) {
+		return fmt.Errorf("sophont code %q should be four letters, the first a capital, as in Luri", sp.Code)
+	}
+	if sp.Name == "" || strings.ContainsAny(sp.Name, " \t") {
+		return fmt.Errorf("sophont %s should have a name of one word, as remarks write it", sp.Code)
+	}
+	switch sp.Kind {
+	case Major, Minor, Extinct, Enclave:
+	default:
+		return fmt.Errorf("sophont %s: kind %q should be major, minor, extinct or enclave", sp.Code, sp.Kind)
+	}
+	for _, digits := range []string{sp.Size, sp.Atmosphere, sp.Hydrographics} {
+		for i := 0; i < len(digits); i++ {
+			if _, err := uwp.Decode(digits[i]); err != nil {
+				return fmt.Errorf("sophont %s: %w", sp.Code, err)
+			}
+		}
+	}
+	return nil
+}
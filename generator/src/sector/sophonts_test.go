@@ -0,0 +1,131 @@
+package sector
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/violetexistence/traveller/generator/sectorfile"
+)
+
+func TestReadSophonts(t *testing.T) {
+	file := "# Sophonts of the Spinward Marches\n" +
+		"Code\tName\tKind\tSize\tAtmosphere\tHydrographics\n" +
+		"Varg\tVargr\tMajor\t\t5678\t\n" +
+		"\n" +
+		"Droy\tDroyne\textinct\t\t\t\n"
+	sophonts, err := ReadSophonts(strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sophonts) != 2 || sophonts[0].Kind != Major || sophonts[0].Atmosphere != "5678" || sophonts[1].Name != "Droyne" {
+		t.Fatalf("read wrongly: %+v", sophonts)
+	}
+
+	for _, bad := range []string{
+		"Code\tName\tKind\nVargr\tVargr\tmajor\n",
+		"Code\tName\tKind\nVARG\tVargr\tmajor\n",
+		"Code\tName\tKind\nVa2g\tVargr\tmajor\n",
+		"Code\tName\tKind\nVarg\t\tmajor\n",
+		"Code\tName\tKind\nVarg\tVargr\tcommon\n",
+		"Code\tName\tKind\tSize\nVarg\tVargr\tmajor\t5?\n",
+		"Code\tName\tColour\nVarg\tVargr\tgrey\n",
+	} {
+		if _, err := ReadSophonts(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestSophontRemark(t *testing.T) {
+	for remark, expected := range map[string]bool{
+		"(Akeed)7":   true,
+		"(Swanfeh)":  true,
+		"[Vargr]":    true,
+		"Di(Droyne)": true,
+		"Luri3":      true,
+		"SwanW":      true,
+		"Akee":       true,
+		"LURI3":      false,
+		"Cp":         false,
+		"Mr(HoPA)":   false,
+		"O:1108":     false,
+		"Hi":         false,
+	} {
+		if sophontRemark(remark) != expected {
+			t.Errorf("%s: expected %v", remark, expected)
+		}
+	}
+}
+
+func TestSettleSophonts(t *testing.T) {
+	s := buildSector(Options{Seed: 2112})
+
+	homeworlds := 0
+	for _, hex := range s.hexes {
+		for _, remark := range strings.Fields(hex.remarks) {
+			if strings.HasPrefix(remark, "(Akeed)") {
+				homeworlds++
+				if !(Sophont{Size: "456789", Atmosphere: "456789", Hydrographics: "6789A"}).suits(hex) {
+					t.Errorf("%s %s does not suit the Akeed", hex.location, hex.name)
+				}
+			}
+		}
+		if _, ok := s.allegiances[hex.allegiance]; !ok {
+			t.Fatalf("%s %s has allegiance %q with no name", hex.location, hex.name, hex.allegiance)
+		}
+		if _, problems := s.roller("").lintWorld(hex, true); len(problems) > 0 {
+			t.Errorf("%s %s: %v", hex.location, hex.name, problems)
+		}
+	}
+	if homeworlds != 1 {
+		t.Fatalf("expected one Akeed homeworld, got %d", homeworlds)
+	}
+
+	// The remarks come back as they were written.
+	var out bytes.Buffer
+	if err := writeSector(&out, s, sectorfile.Tab); err != nil {
+		t.Fatal(err)
+	}
+	file, err := sectorfile.Read(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := fromFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, hex := range loaded.hexes {
+		if hex.remarks != s.hexes[i].remarks {
+			t.Errorf("%s: expected %q, got %q", hex.location, s.hexes[i].remarks, hex.remarks)
+		}
+	}
+}
+
+func TestSophontSharesFitTheWorld(t *testing.T) {
+	var crowd []Sophont
+	for c := 'A'; c <= 'Z'; c++ {
+		code := string(c) + "ooo"
+		crowd = append(crowd, Sophont{Code: code, Name: code, Kind: Enclave})
+	}
+	s := buildSector(Options{Seed: 2112, Sophonts: crowd})
+
+	for _, hex := range s.hexes {
+		tenths := 0
+		for _, remark := range strings.Fields(hex.remarks) {
+			if sophontRemark(remark) && len(remark) == 5 {
+				tenths += strings.IndexByte("0123456789", remark[4])
+			}
+		}
+		if tenths > 10 {
+			t.Errorf("%s %s is settled past its whole population: %q", hex.location, hex.name, hex.remarks)
+		}
+	}
+}
+
+func TestSophontsFollowRules(t *testing.T) {
+	s := newRoller(1, MgT2).settleSophonts(sector{hexes: []hexInfo{{location: "0101", uwp: "A777777-7"}}}, []Sophont{{Code: "Hume", Name: "Human", Kind: Major}})
+	if s.hexes[0].remarks != "" {
+		t.Fatalf("expected no sophont remarks under MgT2, got %q", s.hexes[0].remarks)
+	}
+}
@@ -0,0 +1,384 @@
+package sector
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/violetexistence/traveller/shared/uwp"
+)
+
+// DefaultTolerance is how far, as a share of all worlds, a distribution may
+// stray from the book before Stats calls it drift.
+const DefaultTolerance = 0.05
+
+// chances are the odds of each outcome of a roll, by value.
+type chances map[int]float64
+
+// diceChances are the odds of each total on n six-sided dice.
+func diceChances(n int) chances {
+	c := chances{0: 1}
+	for i := 0; i < n; i++ {
+		next := chances{}
+		for total, p := range c {
+			for face := 1; face <= 6; face++ {
+				next[total+face] += p / 6
+			}
+		}
+		c = next
+	}
+	return c
+}
+
+// fluxChances are the odds of each result of flux, one die less another.
+var fluxChances = diceChances(1).then(func(a int) chances {
+	return diceChances(1).apply(func(b int) int { return a - b })
+})
+
+// apply is the odds of a roll after working each result out from it.
+func (c chances) apply(f func(int) int) chances {
+	next := chances{}
+	for value, p := range c {
+		next[f(value)] += p
+	}
+	return next
+}
+
+// then is the odds of a roll that depends on an earlier one.
+func (c chances) then(f func(int) chances) chances {
+	next := chances{}
+	for value, p := range c {
+		for outcome, q := range f(value) {
+			next[outcome] += p * q
+		}
+	}
+	return next
+}
+
+// expectation is what the book says one UWP element should look like
+// across many worlds.
+type expectation struct {
+	label    string
+	describe string // the roll, for the report
+	element  uwpElementType
+	chances  chances
+}
+
+// expectations are the rolls of one rule set that can be worked out from
+// the tables alone. Tech level, trade codes, zones and importance depend on
+// too much else, so their odds come from the recorded baseline instead.
+func expectations(set RuleSet) []expectation {
+	bookRoll := diceChances(2).apply(func(roll int) int { return roll - 2 })
+	size, population := bookRoll, bookRoll
+	sizeRoll, populationRoll := "2D-2", "2D-2"
+	if set == T5 {
+		size = bookRoll.then(func(roll int) chances {
+			if roll == 10 {
+				return diceChances(1).apply(func(d int) int { return d + 9 })
+			}
+			return chances{roll: 1}
+		})
+		sizeRoll = "2D-2, 10 rerolled as 1D+9"
+		population = bookRoll.then(func(roll int) chances {
+			if roll == 10 {
+				return diceChances(2).apply(func(d int) int { return d + 3 })
+			}
+			return chances{roll: 1}
+		})
+		populationRoll = "2D-2, 10 rerolled as 2D+3"
+	}
+
+	atmosphere := size.then(func(s int) chances {
+		if s == 0 {
+			return chances{0: 1}
+		}
+		return fluxChances.apply(func(f int) int { return applyRange(f+s, 0, 0xF) })
+	})
+
+	// Starports are numbered by their index in portLetters.
+	starport := diceChances(2).apply(func(roll int) int {
+		switch {
+		case roll < 5:
+			return 0
+		case roll < 7:
+			return 1
+		case roll < 9:
+			return 2
+		case roll == 9:
+			return 3
+		case roll < 12:
+			return 4
+		}
+		return 5
+	})
+	starportRoll := "2D: A on 2-4, B 5-6, C 7-8, D 9, E 10-11, X 12"
+	if set == MgT2 {
+		starport = population.then(func(pop int) chances {
+			dm := 0
+			switch {
+			case pop >= 10:
+				dm = 2
+			case pop >= 8:
+				dm = 1
+			case pop >= 3 && pop <= 4:
+				dm = -1
+			case pop < 3:
+				dm = -2
+			}
+			return diceChances(2).apply(func(roll int) int {
+				switch roll += dm; {
+				case roll > 10:
+					return 0
+				case roll > 8:
+					return 1
+				case roll > 6:
+					return 2
+				case roll > 4:
+					return 3
+				case roll > 2:
+					return 4
+				}
+				return 5
+			})
+		})
+		starportRoll = "2D with a DM for population: A on 11+, B 9-10, C 7-8, D 5-6, E 3-4, X 2-"
+	}
+
+	return []expectation{
+		{label: "Starport", describe: starportRoll, element: St, chances: starport},
+		{label: "Size", describe: sizeRoll, element: Siz, chances: size},
+		{label: "Atmosphere", describe: "flux + size, 0 for size 0", element: Atm, chances: atmosphere},
+		{label: "Population", describe: populationRoll, element: Pop, chances: population},
+	}
+}
+
+// portLetters are the starports in the order the expectations number them.
+const portLetters = "ABCDEX"
+
+// tally counts how many worlds have each value of something.
+type tally map[string]int
+
+// measure is something Stats counts about a world, which may have several
+// values at once as trade codes do.
+type measure struct {
+	label   string
+	several bool // whether a world can have more than one value
+	value   func(hexInfo) []string
+}
+
+// sampledMeasures are worked out from everything else about a world, so
+// their odds are taken from the baseline.
+var sampledMeasures = []measure{
+	{"Tech level", false, func(hex hexInfo) []string { return []string{string(hex.uwp[TL])} }},
+	{"Trade codes", true, func(hex hexInfo) []string {
+		var codes []string
+		for _, remark := range strings.Fields(hex.remarks) {
+			code := remarkCode(remark)
+			// Capitals are chosen across the sector, not rolled for a world.
+			if code == subsectorCapitol || code == sectorCapitol || code == capitol {
+				continue
+			}
+			if _, ok := tradeCodeNames[code]; ok {
+				codes = append(codes, string(code))
+			}
+		}
+		return codes
+	}},
+	{"Zone", false, func(hex hexInfo) []string { return []string{string(hex.zone)} }},
+	{"Importance", false, func(hex hexInfo) []string { return []string{strconv.Itoa(hex.importance)} }},
+}
+
+// baselineJSON holds, for each rule set and sampled measure, the share of
+// worlds with each value. It was recorded from a large run of worlds rolled
+// one at a time, so that settling a sector could not skew it, and is
+// recorded again with go test -run TestStatsBaseline -update whenever the
+// tables are meant to change.
+//
+//go:embed stats_baseline.json
+var baselineJSON []byte
+
+// baselines are the recorded odds by rule set, measure and value.
+var baselines = func() map[RuleSet]map[string]map[string]float64 {
+	var b map[RuleSet]map[string]map[string]float64
+	if err := json.Unmarshal(baselineJSON, &b); err != nil {
+		panic(fmt.Sprintf("stats_baseline.json: %v", err))
+	}
+	return b
+}()
+
+// Stats rolls a run of sectors from consecutive seeds and reports how their
+// worlds are spread across starports, sizes, atmospheres, populations,
+// tech levels, trade codes, zones and importance. Where the rule set's
+// tables give the odds outright the report sets them beside what was
+// rolled; the rest are set beside the recorded baseline. A distribution
+// further from its odds than the tolerance is flagged and makes Stats
+// return an error.
+func Stats(w io.Writer, opts Options, sectors int, tolerance float64) error {
+	if sectors < 1 {
+		return fmt.Errorf("stats: expected at least one sector, got %d", sectors)
+	}
+	if opts.Seed == 0 {
+		opts.Seed = newSeed()
+	}
+	set, err := ParseRuleSet(string(opts.Rules))
+	if err != nil {
+		return err
+	}
+
+	var worlds []hexInfo
+	first := opts.Seed
+	for i := 0; i < sectors; i++ {
+		opts.Seed = first + int64(i)
+		worlds = append(worlds, buildSector(opts).hexes...)
+	}
+	if len(worlds) == 0 {
+		return fmt.Errorf("stats: no worlds in %s", plural(sectors, "sector"))
+	}
+
+	fmt.Fprintf(w, "%s of %s rules, seeds %d to %d, %s\n", plural(sectors, "sector"), set, first, opts.Seed, plural(len(worlds), "world"))
+
+	var drifted []string
+	for _, e := range expectations(set) {
+		counts := tally{}
+		for _, hex := range worlds {
+			counts[string(hex.uwp[e.element])]++
+		}
+		expected := map[string]float64{}
+		for value, p := range e.chances {
+			if e.element == St {
+				expected[portLetters[value:value+1]] += p
+			} else {
+				expected[uwp.Encode(value)] += p
+			}
+		}
+
+		d := drift(counts, expected)
+		flag := ""
+		if d > tolerance {
+			flag = "  DRIFT"
+			drifted = append(drifted, e.label)
+		}
+		fmt.Fprintf(w, "\n%s, expected %s: drift %.1f%%%s\n", e.label, e.describe, d*100, flag)
+		writeHistogram(w, counts, len(worlds), expected)
+	}
+
+	for _, m := range sampledMeasures {
+		counts := tally{}
+		for _, hex := range worlds {
+			for _, value := range m.value(hex) {
+				counts[value]++
+			}
+		}
+
+		expected := baselines[set][m.label]
+		d := drift(counts, expected)
+		if m.several {
+			d = codeDrift(counts, len(worlds), expected)
+		}
+		flag := ""
+		if d > tolerance {
+			flag = "  DRIFT"
+			drifted = append(drifted, m.label)
+		}
+		fmt.Fprintf(w, "\n%s, expected from the baseline: drift %.1f%%%s\n", m.label, d*100, flag)
+		writeHistogram(w, counts, len(worlds), expected)
+	}
+
+	if len(drifted) > 0 {
+		return fmt.Errorf("stats: %s drifted more than %.1f%% from the %s rules", strings.Join(drifted, ", "), tolerance*100, set)
+	}
+	return nil
+}
+
+// drift is the total variation distance between what was rolled and what
+// was expected: the share of worlds that would have to change value for
+// the two to agree.
+func drift(counts tally, expected map[string]float64) float64 {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	values := map[string]bool{}
+	for value := range counts {
+		values[value] = true
+	}
+	for value := range expected {
+		values[value] = true
+	}
+
+	var d float64
+	for value := range values {
+		d += math.Abs(float64(counts[value])/float64(total) - expected[value])
+	}
+	return d / 2
+}
+
+// codeDrift is the furthest any one value's share of the worlds is from
+// what was expected, for things like trade codes where a world has any
+// number of values, each of them in or out on its own.
+func codeDrift(counts tally, worlds int, expected map[string]float64) float64 {
+	var d float64
+	for value, p := range expected {
+		d = math.Max(d, math.Abs(float64(counts[value])/float64(worlds)-p))
+	}
+	for value, n := range counts {
+		d = math.Max(d, math.Abs(float64(n)/float64(worlds)-expected[value]))
+	}
+	return d
+}
+
+// writeHistogram lists each value with its share of the worlds, the share
+// expected if there is one, and a bar of one mark for every two percent.
+func writeHistogram(w io.Writer, counts tally, worlds int, expected map[string]float64) {
+	var values []string
+	for value := range counts {
+		values = append(values, value)
+	}
+	for value, p := range expected {
+		if _, ok := counts[value]; !ok && p > 0 {
+			values = append(values, value)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return histogramOrder(values[i], values[j]) })
+
+	header := "         rolled"
+	if expected != nil {
+		header += "  expected"
+	}
+	fmt.Fprintln(w, header)
+
+	for _, value := range values {
+		share := float64(counts[value]) / float64(worlds)
+		line := fmt.Sprintf("  %-4s %6.1f%%", value, share*100)
+		if expected != nil {
+			line += fmt.Sprintf(" %8.1f%%", expected[value]*100)
+		}
+		fmt.Fprintf(w, "%s %s\n", line, strings.Repeat("#", int(math.Round(share*50))))
+	}
+}
+
+// histogramOrder puts numbers in numeric order, eHex digits in eHex order,
+// starports from best to worst and anything else alphabetically.
+func histogramOrder(a string, b string) bool {
+	if x, err := strconv.Atoi(a); err == nil {
+		if y, err := strconv.Atoi(b); err == nil {
+			return x < y
+		}
+	}
+	if len(a) == 1 && len(b) == 1 {
+		if x, y := strings.Index(portLetters, a), strings.Index(portLetters, b); x >= 0 && y >= 0 {
+			return x < y
+		}
+		if x, err := uwp.Decode(a[0]); err == nil {
+			if y, err := uwp.Decode(b[0]); err == nil {
+				return x < y
+			}
+		}
+	}
+	return a < b
+}
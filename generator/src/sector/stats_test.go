@@ -0,0 +1,144 @@
+package sector
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "record stats_baseline.json again from a fresh sample")
+
+// baselineSize is how many worlds of each rule set the baseline is
+// recorded from.
+const baselineSize = 200000
+
+// sample rolls worlds with the rule set's own functions and gives, for each
+// of the sampled measures, the share of those worlds with each value.
+func sample(set RuleSet, seed int64, worlds int) map[string]map[string]float64 {
+	r := newRoller(seed, set)
+	planets := newPlanets(newNameSource(r, false))
+
+	shares := map[string]map[string]float64{}
+	for _, m := range sampledMeasures {
+		shares[m.label] = map[string]float64{}
+	}
+	for n := 0; n < worlds; n++ {
+		hex := r.generateWorld("0101", planets)
+		for _, m := range sampledMeasures {
+			for _, value := range m.value(hex) {
+				shares[m.label][value] += 1 / float64(worlds)
+			}
+		}
+	}
+	for _, values := range shares {
+		for value, p := range values {
+			values[value] = math.Round(p*1e5) / 1e5
+		}
+	}
+	return shares
+}
+
+func TestChances(t *testing.T) {
+	twoDice := diceChances(2)
+	if math.Abs(twoDice[7]-1.0/6) > 1e-9 || math.Abs(twoDice[2]-1.0/36) > 1e-9 {
+		t.Fatalf("2D odds wrong: %v", twoDice)
+	}
+
+	for _, set := range []RuleSet{Classic, MgT2, T5} {
+		for _, e := range expectations(set) {
+			var total float64
+			for _, p := range e.chances {
+				total += p
+			}
+			if math.Abs(total-1) > 1e-9 {
+				t.Errorf("%s %s: odds add up to %f", set, e.label, total)
+			}
+		}
+	}
+}
+
+func TestStatsBaseline(t *testing.T) {
+	if *update {
+		recorded := map[RuleSet]map[string]map[string]float64{}
+		for _, set := range []RuleSet{Classic, MgT2, T5} {
+			recorded[set] = sample(set, 1, baselineSize)
+		}
+		data, err := json.MarshalIndent(recorded, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile("stats_baseline.json", append(data, '\n'), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		t.Skip("recorded stats_baseline.json: rebuild to compare against it")
+	}
+
+	for _, set := range []RuleSet{Classic, MgT2, T5} {
+		for _, m := range sampledMeasures {
+			shares, ok := baselines[set][m.label]
+			if !ok {
+				t.Errorf("%s %s: no baseline", set, m.label)
+				continue
+			}
+			var total float64
+			for _, p := range shares {
+				total += p
+			}
+			if !m.several && math.Abs(total-1) > 1e-3 {
+				t.Errorf("%s %s: baseline odds add up to %f", set, m.label, total)
+			}
+		}
+	}
+
+	// Worlds rolled from an edited table are set against the odds of the
+	// table as it was recorded, so the edit shows.
+	r := newRoller(1, Classic)
+	r.rules.techLevel = (*roller).getTechLevel
+	planets := newPlanets(newNameSource(r, false))
+	counts := tally{}
+	for n := 0; n < 2000; n++ {
+		counts[string(r.generateWorld("0101", planets).uwp[TL])]++
+	}
+	if d := drift(counts, baselines[Classic]["Tech level"]); d <= DefaultTolerance {
+		t.Errorf("expected an edited tech level table to drift, got %.1f%%", d*100)
+	}
+}
+
+func TestDrift(t *testing.T) {
+	if d := drift(tally{"A": 1, "B": 1}, map[string]float64{"A": 0.5, "B": 0.5}); d != 0 {
+		t.Errorf("expected no drift, got %f", d)
+	}
+	if d := drift(tally{"A": 4}, map[string]float64{"B": 1}); d != 1 {
+		t.Errorf("expected complete drift, got %f", d)
+	}
+	if d := drift(tally{"A": 3, "B": 1}, map[string]float64{"A": 0.5, "B": 0.5}); d != 0.25 {
+		t.Errorf("expected a quarter of the worlds to drift, got %f", d)
+	}
+
+	// Trade codes drift by the one furthest out.
+	if d := codeDrift(tally{"Ag": 2, "Ni": 4}, 4, map[string]float64{"Ag": 0.5, "Ni": 0.75, "Hi": 0.1}); d != 0.25 {
+		t.Errorf("expected the furthest code out by a quarter, got %f", d)
+	}
+}
+
+func TestStats(t *testing.T) {
+	var out bytes.Buffer
+	if err := Stats(&out, Options{Seed: 1, Rules: MgT2}, 3, DefaultTolerance); err != nil {
+		t.Fatalf("expected the MgT2 tables to hold: %v\n%s", err, out.String())
+	}
+	for _, heading := range []string{"Starport", "Size", "Atmosphere", "Population", "Tech level", "Trade codes", "Zone", "Importance"} {
+		if !strings.Contains(out.String(), "\n"+heading) {
+			t.Errorf("expected a %s histogram in\n%s", heading, out.String())
+		}
+	}
+
+	// Three sectors never match the odds exactly.
+	out.Reset()
+	if err := Stats(&out, Options{Seed: 1}, 3, 0); err == nil || !strings.Contains(out.String(), "DRIFT") {
+		t.Fatalf("expected drift flagged at no tolerance, got %v", err)
+	}
+}
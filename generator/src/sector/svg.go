@@ -0,0 +1,283 @@
+package sector
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// The map is drawn in the style of the printed subsector maps: flat topped
+// hexes with the odd columns raised, a circle for each world, filled when it
+// has water, and its starport, name and UWP around it.
+const (
+	hexRadius = 40.0 // centre to corner
+	mapMargin = 30.0
+	mapTitle  = 40.0
+)
+
+var hexHeight = hexRadius * math.Sqrt(3)
+
+// borderColors are handed out to allegiances in code order.
+var borderColors = []string{"#c0392b", "#2471a3", "#7d3c98", "#1e8449", "#b9770e", "#117a65", "#a93226", "#2e4053"}
+
+// mapArea is the block of hexes being drawn.
+type mapArea struct {
+	left, top     int // first column and row
+	columns, rows int
+	title         string
+}
+
+// areaOf is the whole sector, or one subsector given its letter A to P.
+func areaOf(s sector, subsector string) (mapArea, error) {
+	if subsector == "" {
+		return mapArea{1, 1, 32, 40, s.name + " Sector"}, nil
+	}
+
+	letter := strings.ToUpper(subsector)
+	if len(letter) != 1 || letter[0] < 'A' || letter[0] > 'P' {
+		return mapArea{}, fmt.Errorf("unknown subsector %q: expected A to P", subsector)
+	}
+
+	i := int(letter[0] - 'A')
+	title := fmt.Sprintf("Subsector %s, %s Sector", letter, s.name)
+	if i < len(s.subsectors) && s.subsectors[i] != "" {
+		title = fmt.Sprintf("%s Subsector (%s), %s Sector", s.subsectors[i], letter, s.name)
+	}
+	return mapArea{i%4*8 + 1, i/4*10 + 1, 8, 10, title}, nil
+}
+
+func (a mapArea) contains(x, y int) bool {
+	return x >= a.left && x < a.left+a.columns && y >= a.top && y < a.top+a.rows
+}
+
+// centre is where a hex sits on the page.
+func (a mapArea) centre(x, y int) (float64, float64) {
+	cx := mapMargin + hexRadius + float64(x-a.left)*1.5*hexRadius
+	cy := mapMargin + mapTitle + hexHeight/2 + float64(y-a.top)*hexHeight
+	if x%2 == 0 {
+		cy += hexHeight / 2
+	}
+	return cx, cy
+}
+
+func (a mapArea) size() (float64, float64) {
+	return mapMargin*2 + (float64(a.columns)*1.5+0.5)*hexRadius,
+		mapMargin*2 + mapTitle + (float64(a.rows)+0.5)*hexHeight
+}
+
+// writeMap draws the sector, or one subsector of it, as an SVG picture with
+// its routes and allegiance borders from the metadata.
+func writeMap(w io.Writer, s sector, subsector string) error {
+	area, err := areaOf(s, subsector)
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	width, height := area.size()
+
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="Helvetica, Arial, sans-serif">`+"\n", width, height, width, height)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="white"/>`+"\n")
+	fmt.Fprintf(&b, `<text x="%.0f" y="%.0f" font-size="24" font-weight="bold">%s</text>`+"\n", mapMargin, mapMargin+20, escape(area.title))
+
+	drawGrid(&b, area)
+	if area.columns > 8 {
+		drawSubsectors(&b, s, area)
+	}
+	drawBorders(&b, s, area)
+	drawRoutes(&b, s, area)
+
+	for _, hex := range s.hexes {
+		if x, y, ok := parseLocationCode(hex.location); ok && area.contains(x, y) {
+			drawWorld(&b, area, x, y, hex)
+		}
+	}
+
+	fmt.Fprintf(&b, "</svg>\n")
+
+	_, err = w.Write(b.Bytes())
+	return err
+}
+
+func drawGrid(b *bytes.Buffer, area mapArea) {
+	fmt.Fprintf(b, `<g fill="none" stroke="#999" stroke-width="1">`+"\n")
+	for x := area.left; x < area.left+area.columns; x++ {
+		for y := area.top; y < area.top+area.rows; y++ {
+			cx, cy := area.centre(x, y)
+			var points []string
+			for corner := 0; corner < 6; corner++ {
+				angle := math.Pi / 3 * float64(corner)
+				points = append(points, fmt.Sprintf("%.1f,%.1f", cx+hexRadius*math.Cos(angle), cy+hexRadius*math.Sin(angle)))
+			}
+			fmt.Fprintf(b, `<polygon points="%s"/>`+"\n", strings.Join(points, " "))
+		}
+	}
+	fmt.Fprintf(b, "</g>\n")
+
+	fmt.Fprintf(b, `<g font-size="9" fill="#888" text-anchor="middle">`+"\n")
+	for x := area.left; x < area.left+area.columns; x++ {
+		for y := area.top; y < area.top+area.rows; y++ {
+			cx, cy := area.centre(x, y)
+			fmt.Fprintf(b, `<text x="%.1f" y="%.1f">%s</text>`+"\n", cx, cy-hexHeight/2+11, getLocationCode(x, y))
+		}
+	}
+	fmt.Fprintf(b, "</g>\n")
+}
+
+// drawSubsectors rules off the subsectors of a whole sector map along the
+// hex edges and names each in its corner.
+func drawSubsectors(b *bytes.Buffer, s sector, area mapArea) {
+	fmt.Fprintf(b, `<g fill="none" stroke="#555" stroke-width="2.5">`+"\n")
+
+	// Down the right hand edges of the last column of each subsector.
+	for x := 8; x < 32; x += 8 {
+		var points []string
+		for y := 1; y <= 40; y++ {
+			cx, cy := area.centre(x, y)
+			points = append(points,
+				fmt.Sprintf("%.1f,%.1f", cx+hexRadius/2, cy-hexHeight/2),
+				fmt.Sprintf("%.1f,%.1f", cx+hexRadius, cy))
+		}
+		cx, cy := area.centre(x, 40)
+		points = append(points, fmt.Sprintf("%.1f,%.1f", cx+hexRadius/2, cy+hexHeight/2))
+		fmt.Fprintf(b, `<polyline points="%s"/>`+"\n", strings.Join(points, " "))
+	}
+
+	// Along the bottom edges of the last row of each subsector.
+	for y := 10; y < 40; y += 10 {
+		var points []string
+		for x := 1; x <= 32; x++ {
+			cx, cy := area.centre(x, y)
+			points = append(points,
+				fmt.Sprintf("%.1f,%.1f", cx-hexRadius/2, cy+hexHeight/2),
+				fmt.Sprintf("%.1f,%.1f", cx+hexRadius/2, cy+hexHeight/2))
+		}
+		fmt.Fprintf(b, `<polyline points="%s"/>`+"\n", strings.Join(points, " "))
+	}
+	fmt.Fprintf(b, "</g>\n")
+
+	fmt.Fprintf(b, `<g font-size="18" fill="#bbb" font-weight="bold">`+"\n")
+	for i, name := range s.subsectors {
+		cx, cy := area.centre(i%4*8+1, i/4*10+1)
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f">%c %s</text>`+"\n", cx-hexRadius/2, cy-hexHeight/4, 'A'+i, escape(name))
+	}
+	fmt.Fprintf(b, "</g>\n")
+}
+
+// drawBorders outlines each allegiance by joining the centres of the hexes
+// along its border.
+func drawBorders(b *bytes.Buffer, s sector, area mapArea) {
+	colors := allegianceColors(s)
+	for _, border := range s.borders {
+		var points []string
+		for _, hex := range border.Hexes() {
+			if x, y, ok := parseLocationCode(hex); ok {
+				cx, cy := area.centre(x, y)
+				points = append(points, fmt.Sprintf("%.1f,%.1f", cx, cy))
+			}
+		}
+		if len(points) < 2 {
+			continue
+		}
+		fmt.Fprintf(b, `<polygon points="%s" fill="%s" fill-opacity="0.08" stroke="%s" stroke-width="4" stroke-opacity="0.6" stroke-linejoin="round"><title>%s</title></polygon>`+"\n",
+			strings.Join(points, " "), colors[border.Allegiance], colors[border.Allegiance], escape(s.allegianceName(border.Allegiance)))
+	}
+}
+
+func allegianceColors(s sector) map[string]string {
+	var codes []string
+	for _, border := range s.borders {
+		codes = append(codes, border.Allegiance)
+	}
+	sort.Strings(codes)
+
+	colors := map[string]string{}
+	for _, code := range codes {
+		if _, ok := colors[code]; !ok {
+			colors[code] = borderColors[len(colors)%len(borderColors)]
+		}
+	}
+	return colors
+}
+
+// routeColors are the colours of routes that do not name their own.
+var routeColors = map[string]string{
+	tradeRoute: "#3a7d44",
+	xboatRoute: "#b03a2e",
+}
+
+func drawRoutes(b *bytes.Buffer, s sector, area mapArea) {
+	fmt.Fprintf(b, `<g stroke-width="3" stroke-linecap="round" stroke-opacity="0.7">`+"\n")
+	for _, route := range s.routes {
+		x1, y1, ok1 := parseLocationCode(route.Start)
+		x2, y2, ok2 := parseLocationCode(route.End)
+		if !ok1 || !ok2 || (!area.contains(x1, y1) && !area.contains(x2, y2)) {
+			continue
+		}
+
+		color := route.Color
+		if color == "" {
+			color = routeColors[route.Type]
+		}
+		if color == "" {
+			color = routeColors[tradeRoute]
+		}
+
+		sx, sy := area.centre(x1, y1)
+		ex, ey := area.centre(x2, y2)
+		fmt.Fprintf(b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s"/>`+"\n", sx, sy, ex, ey, escape(color))
+	}
+	fmt.Fprintf(b, "</g>\n")
+}
+
+// drawWorld marks a world: a zone ring around it, the starport above with
+// any gas giant and bases either side, and the name and UWP below.
+func drawWorld(b *bytes.Buffer, area mapArea, x, y int, hex hexInfo) {
+	cx, cy := area.centre(x, y)
+
+	switch hex.zone {
+	case amberZone:
+		fmt.Fprintf(b, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="none" stroke="#f0a30a" stroke-width="2.5"/>`+"\n", cx, cy+2, hexRadius*0.62)
+	case redZone:
+		fmt.Fprintf(b, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="none" stroke="#d62828" stroke-width="2.5"/>`+"\n", cx, cy+2, hexRadius*0.62)
+	}
+
+	switch {
+	case getNumericUwpValue(hex.uwp, Siz) == 0:
+		// A belt is a scatter of rocks.
+		for _, p := range [][2]float64{{-4, -2}, {3, -4}, {5, 2}, {-2, 4}, {0, 0}} {
+			fmt.Fprintf(b, `<circle cx="%.1f" cy="%.1f" r="1.5" fill="black"/>`+"\n", cx+p[0], cy+p[1])
+		}
+	case getNumericUwpValue(hex.uwp, Hyd) > 0:
+		fmt.Fprintf(b, `<circle cx="%.1f" cy="%.1f" r="6" fill="#1f5fa8" stroke="black"/>`+"\n", cx, cy)
+	default:
+		fmt.Fprintf(b, `<circle cx="%.1f" cy="%.1f" r="6" fill="white" stroke="black" stroke-width="1.5"/>`+"\n", cx, cy)
+	}
+
+	fmt.Fprintf(b, `<text x="%.1f" y="%.1f" font-size="13" font-weight="bold" text-anchor="middle">%c</text>`+"\n", cx, cy-10, hex.uwp[St])
+
+	if hex.gasGiants > 0 {
+		fmt.Fprintf(b, `<circle cx="%.1f" cy="%.1f" r="3" fill="black"><title>Gas giant</title></circle>`+"\n", cx+15, cy-14)
+	}
+	if hex.bases != "" {
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" font-size="10" text-anchor="end" fill="#333"><title>%s</title>%s</text>`+"\n", cx-10, cy-11, escape(describeBases(hex.bases)), escape(hex.bases))
+	}
+
+	name := hex.name
+	if getNumericUwpValue(hex.uwp, Pop) >= 9 {
+		name = strings.ToUpper(name)
+	}
+	fmt.Fprintf(b, `<text x="%.1f" y="%.1f" font-size="10" text-anchor="middle">%s</text>`+"\n", cx, cy+18, escape(name))
+	fmt.Fprintf(b, `<text x="%.1f" y="%.1f" font-size="8" text-anchor="middle" fill="#555">%s</text>`+"\n", cx, cy+28, escape(hex.uwp))
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
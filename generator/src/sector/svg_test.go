@@ -0,0 +1,65 @@
+package sector
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/violetexistence/traveller/generator/sectorfile"
+)
+
+func TestWriteMap(t *testing.T) {
+	s := buildSector(Options{Seed: 4518})
+	s.routes = []sectorfile.Route{{Start: "0101", End: "0203"}}
+	s.borders = []sectorfile.Border{sectorfile.NewBorder("Gc", []string{"0101", "0201", "0202", "0102"})}
+
+	var whole bytes.Buffer
+	if err := writeMap(&whole, s, ""); err != nil {
+		t.Fatal(err)
+	}
+	wellFormed(t, whole.Bytes())
+	if strings.Count(whole.String(), "<polygon") != 32*40+1 {
+		t.Errorf("expected every hex and one border drawn")
+	}
+
+	var page bytes.Buffer
+	if err := writeMap(&page, s, "f"); err != nil {
+		t.Fatal(err)
+	}
+	wellFormed(t, page.Bytes())
+
+	for _, hex := range s.hexes {
+		x, y, _ := parseLocationCode(hex.location)
+		name := escape(hex.name)
+		if getNumericUwpValue(hex.uwp, Pop) >= 9 {
+			name = strings.ToUpper(name)
+		}
+		drawn := strings.Contains(page.String(), ">"+name+"<")
+		if subsectorOf(x, y) == 5 && !drawn {
+			t.Errorf("%s %s is missing from the map of F", hex.location, hex.name)
+		}
+	}
+	if strings.Contains(page.String(), "<line") {
+		t.Error("a route in subsector A was drawn on the map of F")
+	}
+
+	if err := writeMap(io.Discard, s, "Q"); err == nil {
+		t.Error("expected subsector Q to be refused")
+	}
+}
+
+func wellFormed(t *testing.T, svg []byte) {
+	t.Helper()
+	d := xml.NewDecoder(bytes.NewReader(svg))
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatalf("the map is not well formed XML: %v", err)
+		}
+	}
+}
@@ -0,0 +1,398 @@
+package sector
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/violetexistence/traveller/shared/uwp"
+)
+
+// mainworldOrbit is whether the mainworld circles the primary itself or is
+// the satellite of a gas giant or big world.
+type mainworldOrbit int
+
+const (
+	planet mainworldOrbit = iota
+	farSatellite
+	closeSatellite
+)
+
+type bodyKind string
+
+const (
+	mainworldBody bodyKind = "Mainworld"
+	gasGiantBody           = "Gas giant"
+	beltBody               = "Planetoid belt"
+	bigWorldBody           = "Big world"
+	worldBody              = "World"
+	starBody               = "Star"
+)
+
+// body is anything in orbit: a star, a world, a belt or a gas giant, along
+// with its own satellites.
+type body struct {
+	kind       bodyKind
+	orbit      int // around the primary
+	name       string
+	uwp        string // mini UWP, or the size of a gas giant
+	close      bool   // a close satellite, tidally locked to its parent
+	satellites []body
+}
+
+// starSystem lays out everything circling the primary.
+type starSystem struct {
+	habitable int // orbit of the habitable zone
+	mainworld mainworldOrbit
+	companion *body  // the primary's own companion, beside it rather than in orbit
+	bodies    []body // in orbit order
+}
+
+const maxOrbit = 19
+
+// habitableOrbits is the habitable zone orbit of a main sequence star, early
+// (0-4) and late (5-9) in its spectral class.
+var habitableOrbits = map[string][2]int{
+	"O": {15, 14},
+	"B": {13, 12},
+	"A": {8, 7},
+	"F": {6, 5},
+	"G": {3, 2},
+	"K": {2, 1},
+	"M": {0, 0},
+}
+
+// habitableSizeShift moves the habitable zone out for brighter, larger stars.
+var habitableSizeShift = map[string]int{
+	"Ia":  5,
+	"Ib":  4,
+	"II":  3,
+	"III": 2,
+	"IV":  1,
+	"VI":  -1,
+}
+
+func habitableOrbit(s star) int {
+	if s.size == "D" {
+		return 0
+	}
+
+	orbits := habitableOrbits[s.class.letter]
+	orbit := orbits[0]
+	if s.class.numeral > 4 {
+		orbit = orbits[1]
+	}
+
+	return applyRange(orbit+habitableSizeShift[s.size], 0, maxOrbit)
+}
+
+func (r *roller) rollMainworldOrbit() mainworldOrbit {
+	switch roll := r.flux(); {
+	case roll < -3:
+		return farSatellite
+	case roll == -3:
+		return closeSatellite
+	}
+	return planet
+}
+
+func mainworldIs(orbits ...mainworldOrbit) requirement {
+	return func(w hexInfo) bool {
+		for _, o := range orbits {
+			if w.system.mainworld == o {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// generateSystem places the mainworld, gas giants, belts and other worlds
+// counted by the PBG and W columns. The mainworld sits hzVar orbits from the
+// habitable zone. A satellite mainworld circles a gas giant, or a big world
+// when there are no gas giants, and becomes a planet when there is neither.
+func (r *roller) generateSystem(hex hexInfo) starSystem {
+	s := starSystem{
+		habitable: habitableOrbit(hex.primary),
+		mainworld: hex.system.mainworld,
+	}
+
+	occupied := map[int]bool{}
+	place := func(preferred int) int {
+		preferred = applyRange(preferred, 0, maxOrbit)
+		for step := 0; step <= maxOrbit; step++ {
+			for _, orbit := range []int{preferred + step, preferred - step} {
+				if orbit >= 0 && orbit <= maxOrbit && !occupied[orbit] {
+					occupied[orbit] = true
+					return orbit
+				}
+			}
+		}
+		return preferred
+	}
+
+	// Stars in orbit take their orbits first, with any companion of their
+	// own beside them. A companion listed first belongs to the primary.
+	for i, c := range hex.companions {
+		if c.position == companionStar {
+			if i == 0 {
+				companion := starOf(c)
+				s.companion = &companion
+			} else if n := len(s.bodies); n > 0 && s.bodies[n-1].orbit == c.orbit {
+				s.bodies[n-1] = moonOf(s.bodies[n-1], starOf(c), true)
+			}
+			continue
+		}
+		if c.orbit <= maxOrbit {
+			occupied[c.orbit] = true
+		}
+		s.bodies = append(s.bodies, starOf(c))
+	}
+
+	others := applyMinimum(hex.worlds-1-hex.gasGiants-hex.belts, 0)
+	gasGiants := hex.gasGiants
+	mainworldAt := s.habitable + hex.hzVar
+
+	mainworld := body{kind: mainworldBody, name: hex.name, uwp: hex.uwp}
+
+	switch {
+	case s.mainworld == planet:
+		mainworld.orbit = place(mainworldAt)
+		s.bodies = append(s.bodies, mainworld)
+	case gasGiants > 0:
+		parent := r.rollGasGiant(place(mainworldAt))
+		gasGiants--
+		s.bodies = append(s.bodies, moonOf(parent, mainworld, s.mainworld == closeSatellite))
+	case others > 0:
+		parent := r.rollSecondary(bigWorldBody, place(mainworldAt), s.habitable, hex)
+		others--
+		s.bodies = append(s.bodies, moonOf(parent, mainworld, s.mainworld == closeSatellite))
+	default:
+		s.mainworld = planet
+		mainworld.orbit = place(mainworldAt)
+		s.bodies = append(s.bodies, mainworld)
+	}
+
+	for i := 0; i < gasGiants; i++ {
+		s.bodies = append(s.bodies, r.rollGasGiant(place(s.habitable+r.dice(2)-5)))
+	}
+
+	for i := 0; i < hex.belts; i++ {
+		s.bodies = append(s.bodies, r.rollSecondary(beltBody, place(s.habitable+r.dice(2)-3), s.habitable, hex))
+	}
+
+	for i := 0; i < others; i++ {
+		var giants []int
+		for j, b := range s.bodies {
+			if b.kind == gasGiantBody {
+				giants = append(giants, j)
+			}
+		}
+
+		if len(giants) > 0 && r.dice(1) < 3 {
+			parent := &s.bodies[giants[r.rng.Intn(len(giants))]]
+			moon := r.rollSecondary(worldBody, parent.orbit, s.habitable, hex)
+			*parent = moonOf(*parent, moon, r.dice(1) < 4)
+			continue
+		}
+
+		s.bodies = append(s.bodies, r.rollSecondary(worldBody, place(s.habitable+r.flux()), s.habitable, hex))
+	}
+
+	sort.Slice(s.bodies, func(i, j int) bool {
+		return s.bodies[i].orbit < s.bodies[j].orbit
+	})
+
+	s.name(hex.name)
+
+	return s
+}
+
+func starOf(c companion) body {
+	return body{
+		kind:  starBody,
+		orbit: c.orbit,
+		name:  fmt.Sprintf("%s%s star", strings.ToUpper(string(c.position[:1])), c.position[1:]),
+		uwp:   formatStar(c.star),
+	}
+}
+
+func moonOf(parent body, moon body, close bool) body {
+	moon.orbit = parent.orbit
+	moon.close = close
+	parent.satellites = append(parent.satellites, moon)
+	return parent
+}
+
+func (r *roller) rollGasGiant(orbit int) body {
+	size := "LGG"
+	if r.dice(1) < 3 {
+		size = "SGG"
+	}
+	return body{kind: gasGiantBody, orbit: orbit, uwp: size}
+}
+
+// rollSecondary rolls a mini UWP for a world other than the mainworld. Nobody
+// outnumbers or out-builds the mainworld, and worlds inside the habitable
+// zone are too hot to hold water.
+func (r *roller) rollSecondary(kind bodyKind, orbit int, habitable int, hex hexInfo) body {
+	var size worldSize
+	switch kind {
+	case beltBody:
+		size = 0
+	case bigWorldBody:
+		size = worldSize(applyMaximum(r.dice(2)+7, 0xF))
+	default:
+		size = worldSize(r.dice(2) - 2)
+	}
+
+	var atmosphere atmosphereType
+	var hydrographics hydrographicType
+	if kind != beltBody {
+		atmosphere = r.rules.atmosphere(r, size)
+		hydrographics = r.rules.hydrographics(r, size, atmosphere)
+		if orbit < habitable {
+			hydrographics = 0
+		}
+	}
+
+	mainPopulation := getNumericUwpValue(hex.uwp, Pop)
+	var population, government, law, tech int
+	if mainPopulation > 0 && r.dice(1) > 3 {
+		population = applyRange(mainPopulation-r.dice(1), 0, mainPopulation-1)
+	}
+	if population > 0 {
+		government = int(r.rules.government(r, populationType(population)))
+		law = int(r.rules.law(r, populationType(population), governmentType(government)))
+		tech = applyMinimum(getNumericUwpValue(hex.uwp, TL)-1, 0)
+	}
+
+	profile := uwp.UWP{
+		Starport:      r.rollSpaceport(population),
+		Size:          int(size),
+		Atmosphere:    int(atmosphere),
+		Hydrographics: int(hydrographics),
+		Population:    population,
+		Government:    government,
+		Law:           law,
+		TechLevel:     tech,
+	}
+
+	return body{kind: kind, orbit: orbit, uwp: profile.String()}
+}
+
+// rollSpaceport rolls the facilities on a secondary world: F is good, G
+// poor, H primitive and Y none.
+func (r *roller) rollSpaceport(population int) byte {
+	switch roll := population - r.dice(1); {
+	case population == 0:
+		return 'Y'
+	case roll > 3:
+		return 'F'
+	case roll == 3:
+		return 'G'
+	case roll > 0:
+		return 'H'
+	}
+	return 'Y'
+}
+
+// name counts bodies outward from the primary, Sol style, with satellites
+// lettered after their parent. The mainworld keeps its own name.
+func (s *starSystem) name(system string) {
+	var planets int
+	for i := range s.bodies {
+		b := &s.bodies[i]
+		if b.kind == starBody {
+			continue
+		}
+		planets++
+		if b.kind != mainworldBody {
+			b.name = fmt.Sprintf("%s %s", system, roman(planets))
+		}
+		for j := range b.satellites {
+			moon := &b.satellites[j]
+			if moon.kind != mainworldBody {
+				moon.name = fmt.Sprintf("%s %c", b.name, 'a'+j)
+			}
+		}
+	}
+}
+
+func roman(n int) string {
+	numerals := []struct {
+		value   int
+		numeral string
+	}{{10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"}, {1, "I"}}
+
+	var b strings.Builder
+	for _, r := range numerals {
+		for ; n >= r.value; n -= r.value {
+			b.WriteString(r.numeral)
+		}
+	}
+	return b.String()
+}
+
+func describeMainworldOrbit(hex hexInfo) string {
+	orbit := fmt.Sprintf("%d worlds, mainworld orbits the primary", hex.worlds)
+	for _, b := range hex.system.bodies {
+		for _, moon := range b.satellites {
+			if moon.kind == mainworldBody {
+				orbit = fmt.Sprintf("%d worlds, mainworld is a satellite of %s", hex.worlds, b.name)
+			}
+		}
+	}
+	return orbit
+}
+
+// describeSystem lists the system one body to a line, satellites indented
+// under their parent.
+func describeSystem(hex hexInfo) []string {
+	lines := []string{
+		fmt.Sprintf("%s %s, habitable zone at orbit %d", hex.name, hex.stars, hex.system.habitable),
+	}
+
+	if len(hex.system.bodies) == 0 {
+		return append(lines, "  No survey of the system")
+	}
+
+	if c := hex.system.companion; c != nil {
+		lines = append(lines, fmt.Sprintf("   -  %-24s %-9s  %s, companion to the primary", c.name, c.uwp, c.kind))
+	}
+
+	for _, b := range hex.system.bodies {
+		lines = append(lines, fmt.Sprintf("  %2d  %-24s %-9s  %s", b.orbit, b.name, b.uwp, b.kind))
+		for _, moon := range b.satellites {
+			distance := "far"
+			if moon.close {
+				distance = "close"
+			}
+			kind := string(moon.kind)
+			if moon.kind == worldBody {
+				kind = "Satellite"
+			}
+			lines = append(lines, fmt.Sprintf("        %-22s %-9s  %s, %s orbit", moon.name, moon.uwp, kind, distance))
+		}
+	}
+
+	return lines
+}
+
+// writeSystems lists every system in the sector, a blank line between each.
+func writeSystems(w io.Writer, s sector) error {
+	for i, hex := range s.hexes {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		lines := describeSystem(hex)
+		lines[0] = hex.location + " " + lines[0]
+		if _, err := io.WriteString(w, strings.Join(lines, "\n")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package sector
+
+import (
+	"strings"
+	"testing"
+)
+
+func countBodies(bodies []body) int {
+	var n int
+	for _, b := range bodies {
+		if b.kind != gasGiantBody && b.kind != starBody {
+			n++
+		}
+		n += countBodies(b.satellites)
+	}
+	return n
+}
+
+func countStars(bodies []body) int {
+	var n int
+	for _, b := range bodies {
+		if b.kind == starBody {
+			n++
+		}
+		n += countStars(b.satellites)
+	}
+	return n
+}
+
+func TestGenerateSystem(t *testing.T) {
+	s := buildSector(Options{Seed: 2024})
+
+	var satellites int
+	for _, hex := range s.hexes {
+		bodies := countBodies(hex.system.bodies) + hex.gasGiants
+		if bodies != hex.worlds {
+			t.Fatalf("%s %s has %d bodies but W is %d:\n%s", hex.location, hex.name, bodies, hex.worlds, strings.Join(describeSystem(hex), "\n"))
+		}
+
+		orbits := map[int]bool{}
+		for _, b := range hex.system.bodies {
+			if orbits[b.orbit] {
+				t.Fatalf("%s %s has two bodies in orbit %d", hex.location, hex.name, b.orbit)
+			}
+			orbits[b.orbit] = true
+		}
+
+		isSatellite := hex.system.mainworld != planet
+		if isSatellite != hasTradeCode(satellite)(hex) {
+			t.Fatalf("%s %s satellite %v does not match remarks %q", hex.location, hex.name, isSatellite, hex.remarks)
+		}
+		if hex.system.mainworld == closeSatellite != hasTradeCode(locked)(hex) {
+			t.Fatalf("%s %s locked does not match remarks %q", hex.location, hex.name, hex.remarks)
+		}
+		if isSatellite {
+			satellites++
+		}
+	}
+
+	if satellites == 0 {
+		t.Fatal("expected some satellite mainworlds in a whole sector")
+	}
+}
+
+func TestHabitableOrbit(t *testing.T) {
+	tests := []struct {
+		star     star
+		expected int
+	}{
+		{star{class: spectralClass{letter: "G", numeral: 2}, size: "V"}, 3},
+		{star{class: spectralClass{letter: "K", numeral: 7}, size: "III"}, 3},
+		{star{class: spectralClass{letter: "M", numeral: 4}, size: "VI"}, 0},
+		{star{class: spectralClass{letter: "M"}, size: "D"}, 0},
+	}
+
+	for _, test := range tests {
+		if actual := habitableOrbit(test.star); actual != test.expected {
+			t.Errorf("%s: expected orbit %d, got %d", formatStar(test.star), test.expected, actual)
+		}
+	}
+}
+
+func TestCompanionsInStellar(t *testing.T) {
+	s := buildSector(Options{Seed: 2024})
+
+	var multiple int
+	for _, hex := range s.hexes {
+		if hex.stars != formatStars(hex.primary, hex.companions) {
+			t.Fatalf("%s %s stellar %q does not list its companions", hex.location, hex.name, hex.stars)
+		}
+		if len(hex.companions) > 0 {
+			multiple++
+		}
+
+		stars := countStars(hex.system.bodies)
+		if hex.system.companion != nil {
+			stars++
+		}
+		if stars != len(hex.companions) {
+			t.Fatalf("%s %s has %d companions but %d in its system:\n%s", hex.location, hex.name, len(hex.companions), stars, strings.Join(describeSystem(hex), "\n"))
+		}
+	}
+
+	if multiple == 0 || multiple == len(s.hexes) {
+		t.Fatalf("expected some but not all systems to have companions, got %d of %d", multiple, len(s.hexes))
+	}
+}
@@ -0,0 +1,354 @@
+package sector
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/violetexistence/traveller/shared/uwp"
+)
+
+// worldField is one line of the world sheet. Rerolling it replaces the
+// field and recomputes everything derived from it.
+type worldField struct {
+	label    string
+	describe func(h hexInfo) string
+	reroll   func(r *roller, h hexInfo) hexInfo
+}
+
+func uwpValue(element uwpElementType) func(h hexInfo) int {
+	return func(h hexInfo) int {
+		return getNumericUwpValue(h.uwp, element)
+	}
+}
+
+// setUwpValue replaces one element of a UWP.
+func setUwpValue(uwp string, element uwpElementType, value string) string {
+	return uwp[:element] + value + uwp[element+1:]
+}
+
+func rerollUwp(element uwpElementType, roll func(r *roller, h hexInfo) int) func(r *roller, h hexInfo) hexInfo {
+	return func(r *roller, h hexInfo) hexInfo {
+		h.uwp = setUwpValue(h.uwp, element, uwp.Encode(roll(r, h)))
+		return r.refreshWorld(h)
+	}
+}
+
+func uwpLine(element uwpElementType, describe func(int) string) func(h hexInfo) string {
+	return func(h hexInfo) string {
+		value := string(h.uwp[element])
+		return fmt.Sprintf("%s: %s", value, describe(getNumericUwpValue(h.uwp, element)))
+	}
+}
+
+var (
+	sizeOf       = uwpValue(Siz)
+	atmosphereOf = uwpValue(Atm)
+	populationOf = uwpValue(Pop)
+	governmentOf = uwpValue(Gov)
+)
+
+func (r *roller) techRoll(h hexInfo) int {
+	return int(r.rules.techLevel(r,
+		starportClass(h.uwp[St:St+1]),
+		worldSize(sizeOf(h)),
+		atmosphereType(atmosphereOf(h)),
+		hydrographicType(getNumericUwpValue(h.uwp, Hyd)),
+		populationType(populationOf(h)),
+		governmentType(governmentOf(h)),
+	))
+}
+
+var worldFields = []worldField{
+	{
+		label: "Starport",
+		describe: func(h hexInfo) string {
+			return fmt.Sprintf("%c: %s", h.uwp[St], uwp.DescribeStarport(h.uwp[St]))
+		},
+		reroll: func(r *roller, h hexInfo) hexInfo {
+			h.uwp = setUwpValue(h.uwp, St, string(r.rules.starport(r, populationType(populationOf(h)))))
+			h.bases = r.rules.bases(r, starportClass(h.uwp[St:St+1]), populationType(populationOf(h)), lawLevel(getNumericUwpValue(h.uwp, Law)))
+			return r.refreshWorld(h)
+		},
+	},
+	{
+		label:    "Size",
+		describe: uwpLine(Siz, uwp.DescribeSize),
+		reroll:   rerollUwp(Siz, func(r *roller, _ hexInfo) int { return int(r.rules.size(r)) }),
+	},
+	{
+		label:    "Atmosphere",
+		describe: uwpLine(Atm, uwp.DescribeAtmosphere),
+		reroll: rerollUwp(Atm, func(r *roller, h hexInfo) int {
+			return int(r.rules.atmosphere(r, worldSize(sizeOf(h))))
+		}),
+	},
+	{
+		label:    "Hydrographics",
+		describe: uwpLine(Hyd, uwp.DescribeHydrographics),
+		reroll: rerollUwp(Hyd, func(r *roller, h hexInfo) int {
+			return int(r.rules.hydrographics(r, worldSize(sizeOf(h)), atmosphereType(atmosphereOf(h))))
+		}),
+	},
+	{
+		label:    "Population",
+		describe: uwpLine(Pop, uwp.DescribePopulation),
+		reroll:   rerollUwp(Pop, func(r *roller, _ hexInfo) int { return int(r.rules.population(r)) }),
+	},
+	{
+		label:    "Government",
+		describe: uwpLine(Gov, uwp.DescribeGovernment),
+		reroll: rerollUwp(Gov, func(r *roller, h hexInfo) int {
+			return int(r.rules.government(r, populationType(populationOf(h))))
+		}),
+	},
+	{
+		label:    "Law level",
+		describe: uwpLine(Law, uwp.DescribeLaw),
+		reroll: rerollUwp(Law, func(r *roller, h hexInfo) int {
+			return int(r.rules.law(r, populationType(populationOf(h)), governmentType(governmentOf(h))))
+		}),
+	},
+	{
+		label:    "Tech level",
+		describe: uwpLine(TL, uwp.DescribeTechLevel),
+		reroll:   rerollUwp(TL, (*roller).techRoll),
+	},
+	{
+		label: "Bases",
+		describe: func(h hexInfo) string {
+			return describeBases(h.bases)
+		},
+		reroll: func(r *roller, h hexInfo) hexInfo {
+			h.bases = r.rules.bases(r, starportClass(h.uwp[St:St+1]), populationType(populationOf(h)), lawLevel(getNumericUwpValue(h.uwp, Law)))
+			return r.refreshWorld(h)
+		},
+	},
+	{
+		label: "Stars",
+		describe: func(h hexInfo) string {
+			return fmt.Sprintf("%s, %s", h.stars, describeHzVar(h.hzVar))
+		},
+		reroll: func(r *roller, h hexInfo) hexInfo {
+			primary, spectralFlux, sizeFlux := r.rollPrimary()
+			h.primary = primary
+			h.companions = r.getCompanions(spectralFlux, sizeFlux)
+			h.stars = formatStars(h.primary, h.companions)
+			h.hzVar = r.getHzVar(h.primary, h.companions...)
+			h.system = r.generateSystem(h)
+			return r.refreshWorld(h)
+		},
+	},
+	{
+		label: "System",
+		describe: func(h hexInfo) string {
+			return describeMainworldOrbit(h)
+		},
+		reroll: func(r *roller, h hexInfo) hexInfo {
+			h.system.mainworld = r.rollMainworldOrbit()
+			h.system = r.generateSystem(h)
+			return r.refreshWorld(h)
+		},
+	},
+	{
+		label: "Zone",
+		describe: func(h hexInfo) string {
+			return describeZone(h.zone)
+		},
+		reroll: func(r *roller, h hexInfo) hexInfo {
+			h.zone = r.rules.zone(r, h)
+			return h
+		},
+	},
+	{
+		label: "Economics",
+		describe: func(h hexInfo) string {
+			return fmt.Sprintf("%s resources %d, labour %d, infrastructure %d, efficiency %+d",
+				toFileWorld(h).Ex, h.resources, h.labor, h.infrastructure, h.efficiencies)
+		},
+		reroll: func(r *roller, h hexInfo) hexInfo {
+			h.resources = r.getResources(h)
+			h.infrastructure = r.getInfrastructure(h)
+			h.efficiencies = r.getEfficiencies(h)
+			return r.refreshWorld(h)
+		},
+	},
+	{
+		label: "Culture",
+		describe: func(h hexInfo) string {
+			return fmt.Sprintf("%s heterogeneity %d, acceptance %d, strangeness %d, symbols %d",
+				toFileWorld(h).Cx, h.heterogeneity, h.acceptance, h.strangeness, h.symbols)
+		},
+		reroll: func(r *roller, h hexInfo) hexInfo {
+			h.heterogeneity = r.getHeterogeneity(h)
+			h.strangeness = r.getStrangeness(h)
+			h.symbols = r.getSymbols(h)
+			return r.refreshWorld(h)
+		},
+	},
+}
+
+// refreshWorld recomputes everything that follows directly from the UWP,
+// bases and stars, keeping the fields that were rolled.
+func (r *roller) refreshWorld(h hexInfo) hexInfo {
+	// getTradeCodes looks at the remarks already present, so start clean.
+	h.remarks = ""
+	h.remarks = r.getTradeCodes(h)
+	h.importance = getImportanceExtension(h)
+	h.labor = getLabor(h)
+	h.acceptance = getAcceptance(h)
+	h.nobility = getNobility(h)
+	if getNumericUwpValue(h.uwp, Pop) == 0 {
+		h.populationMultiplier = 0
+	} else if h.populationMultiplier == 0 {
+		h.populationMultiplier = r.getPopulationMultiplier(h)
+	}
+	return h
+}
+
+// NewWorld creates the single world generator.
+func NewWorld(opts Options) tea.Model {
+	if opts.Seed == 0 {
+		opts.Seed = newSeed()
+	}
+
+	return worldModel{
+		help: help.New(),
+		opts: opts,
+	}
+}
+
+type worldModel struct {
+	help   help.Model
+	opts   Options
+	world  hexInfo
+	roll   *roller // for rerolls of the world's fields
+	notice string
+	cursor int
+}
+
+type worldKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Reroll key.Binding
+	New    key.Binding
+}
+
+func (k worldKeyMap) shortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Reroll, k.New}
+}
+
+var worldKeys = worldKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("k", "up"),
+		key.WithHelp("↑/k", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("j", "down"),
+		key.WithHelp("↓/j", "down"),
+	),
+	Reroll: key.NewBinding(
+		key.WithKeys("r", "enter"),
+		key.WithHelp("r", "reroll field"),
+	),
+	New: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "new world"),
+	),
+}
+
+// rolledWorld is a world rolled in the background, with the roller that
+// rolled it, which rerolls of its fields carry on from, and anything to tell
+// about how it was rolled.
+type rolledWorld struct {
+	world  hexInfo
+	roll   *roller
+	notice string
+}
+
+func rollWorld(opts Options) tea.Cmd {
+	return func() tea.Msg {
+		r := newRoller(opts.Seed, opts.Rules)
+		planets := newPlanets(newNameSource(r, opts.Online))
+		world := r.generateWorld("0101", planets)
+		if opts.Name != "" {
+			world.name = opts.Name
+		}
+		return rolledWorld{world: world, roll: r, notice: planets.notice()}
+	}
+}
+
+func (m worldModel) Init() tea.Cmd {
+	return rollWorld(m.opts)
+}
+
+func (m worldModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case rolledWorld:
+		m.world = msg.world
+		m.roll = msg.roll
+		m.notice = msg.notice
+	case tea.KeyMsg:
+		if m.world.uwp == "" {
+			break
+		}
+
+		switch {
+		case key.Matches(msg, worldKeys.Up):
+			m.cursor = applyMinimum(m.cursor-1, 0)
+		case key.Matches(msg, worldKeys.Down):
+			m.cursor = applyMaximum(m.cursor+1, len(worldFields)-1)
+		case key.Matches(msg, worldKeys.Reroll):
+			m.world = worldFields[m.cursor].reroll(m.roll, m.world)
+		case key.Matches(msg, worldKeys.New):
+			m.opts.Seed = newSeed()
+			return m, m.Init()
+		}
+	}
+
+	return m, nil
+}
+
+var selectedField = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+
+func (m worldModel) View() string {
+	if m.world.uwp == "" {
+		return "\n\nRolling a world..."
+	}
+
+	w := m.world
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "\n\n%s %s (seed %d)\n\n", w.name, w.uwp, m.opts.Seed)
+
+	for i, field := range worldFields {
+		cursor := " "
+		line := fmt.Sprintf("%-14s %s", field.label, field.describe(w))
+		if i == m.cursor {
+			cursor = ">"
+			line = selectedField.Render(line)
+		}
+		fmt.Fprintf(&b, "%s %s\n", cursor, line)
+	}
+
+	fmt.Fprintf(&b, "\n  %-14s %d (%s)\n", "Importance", w.importance, toFileWorld(w).Ix)
+	fmt.Fprintf(&b, "  %-14s %s\n", "Nobility", describeNobility(w.nobility))
+	fmt.Fprintf(&b, "  %-14s %s\n", "PBG", toFileWorld(w).PBG)
+
+	fmt.Fprintf(&b, "\n  Trade codes\n")
+	for _, code := range describeRemarks(w.remarks) {
+		fmt.Fprintf(&b, "    %s\n", code)
+	}
+
+	fmt.Fprintf(&b, "\n  %s\n", strings.Join(describeSystem(w), "\n  "))
+
+	if m.notice != "" {
+		fmt.Fprintf(&b, "\n %s\n", m.notice)
+	}
+	fmt.Fprintf(&b, "\n %s", m.help.ShortHelpView(worldKeys.shortHelp()))
+
+	return b.String()
+}
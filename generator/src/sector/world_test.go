@@ -0,0 +1,25 @@
+package sector
+
+import "testing"
+
+func TestRerollKeepsWorldConsistent(t *testing.T) {
+	r := newRoller(8, T5)
+	world := r.generateWorld("0101", newPlanets(newNameSource(r, false)))
+
+	for i := 0; i < 200; i++ {
+		field := worldFields[i%len(worldFields)]
+		world = field.reroll(r, world)
+
+		if len(world.uwp) != 9 || world.uwp[7] != '-' {
+			t.Fatalf("rerolling %s broke the UWP: %q", field.label, world.uwp)
+		}
+		fresh := world
+		fresh.remarks = ""
+		if world.remarks != r.getTradeCodes(fresh) {
+			t.Fatalf("rerolling %s left stale trade codes %q on %s", field.label, world.remarks, world.uwp)
+		}
+		if world.importance != getImportanceExtension(world) {
+			t.Fatalf("rerolling %s left a stale importance on %s", field.label, world.uwp)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+// Package sectorfile reads and writes sector data in the T5 Second Survey
+// layouts that Traveller Map accepts: tab delimited and fixed width columns.
+// It also reads the classic SEC layout.
+package sectorfile
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Format names a sector file layout.
+type Format string
+
+const (
+	Tab    Format = "t5tab"
+	Column Format = "column"
+	Legacy Format = "sec" // read only
+)
+
+// ParseFormat checks a format name given on the command line.
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(name); format {
+	case Tab, Column:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown format %q", name)
+}
+
+// World is one row of a sector file, holding the text of each column as it
+// appears in T5 Second Survey data.
+type World struct {
+	Hex        string
+	Name       string
+	UWP        string
+	Remarks    string
+	Ix         string // "{ 2 }"
+	Ex         string // "(B6B+3)"
+	Cx         string // "[896A]"
+	Nobility   string
+	Bases      string
+	Zone       string
+	PBG        string
+	W          string
+	Allegiance string
+	Stars      string
+}
+
+// File is a whole sector file. Comments are the lines that started with #,
+// without the marker. Format is the layout the file was read from.
+type File struct {
+	Comments []string
+	Worlds   []World
+	Format   Format
+}
+
+type column struct {
+	tab   string // T5 tab delimited header
+	short string // fixed width header
+	empty string // placeholder for an empty fixed width cell
+	width int    // narrowest fixed width column
+	get   func(w *World) *string
+}
+
+// columns are in T5 Second Survey order.
+var columns = []column{
+	{tab: "Hex", short: "Hex", get: func(w *World) *string { return &w.Hex }},
+	{tab: "Name", short: "Name", width: 20, get: func(w *World) *string { return &w.Name }},
+	{tab: "UWP", short: "UWP", get: func(w *World) *string { return &w.UWP }},
+	{tab: "Remarks", short: "Remarks", get: func(w *World) *string { return &w.Remarks }},
+	{tab: "{Ix}", short: "{Ix}", get: func(w *World) *string { return &w.Ix }},
+	{tab: "(Ex)", short: "(Ex)", get: func(w *World) *string { return &w.Ex }},
+	{tab: "[Cx]", short: "[Cx]", get: func(w *World) *string { return &w.Cx }},
+	{tab: "Nobility", short: "N", empty: "-", get: func(w *World) *string { return &w.Nobility }},
+	{tab: "Bases", short: "B", empty: "-", get: func(w *World) *string { return &w.Bases }},
+	{tab: "Zone", short: "Z", empty: "-", get: func(w *World) *string { return &w.Zone }},
+	{tab: "PBG", short: "PBG", get: func(w *World) *string { return &w.PBG }},
+	{tab: "W", short: "W", get: func(w *World) *string { return &w.W }},
+	{tab: "Allegiance", short: "A", get: func(w *World) *string { return &w.Allegiance }},
+	{tab: "Stars", short: "Stellar", get: func(w *World) *string { return &w.Stars }},
+}
+
+// Write writes the file in the given format.
+func Write(w io.Writer, f File, format Format) error {
+	switch format {
+	case Tab:
+		return WriteTab(w, f)
+	case Column, Legacy:
+		return WriteColumns(w, f)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+func writeComments(w io.Writer, f File) error {
+	for _, c := range f.Comments {
+		if _, err := fmt.Fprintf(w, "#%s\n", c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteTab writes the T5 tab delimited layout used by Traveller Map's custom
+// data upload.
+func WriteTab(w io.Writer, f File) error {
+	if err := writeComments(w, f); err != nil {
+		return err
+	}
+
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.tab
+	}
+	if _, err := fmt.Fprintln(w, strings.Join(header, "\t")); err != nil {
+		return err
+	}
+
+	for _, world := range f.Worlds {
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			row[i] = *c.get(&world)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteColumns writes the fixed width T5 column layout, each column as wide
+// as its widest value and underlined with dashes.
+func WriteColumns(w io.Writer, f File) error {
+	if err := writeComments(w, f); err != nil {
+		return err
+	}
+
+	cell := func(c column, world *World) string {
+		value := *c.get(world)
+		if value == "" {
+			return c.empty
+		}
+		return value
+	}
+
+	widths := make([]int, len(columns))
+	for i, c := range columns {
+		widths[i] = max(c.width, len(c.short))
+		for _, world := range f.Worlds {
+			widths[i] = max(widths[i], len(cell(c, &world)))
+		}
+	}
+
+	line := func(value func(i int, c column) string) string {
+		cells := make([]string, len(columns))
+		for i, c := range columns {
+			cells[i] = fmt.Sprintf("%-*s", widths[i], value(i, c))
+		}
+		return strings.Join(cells, " ")
+	}
+
+	lines := []string{
+		line(func(_ int, c column) string { return c.short }),
+		line(func(i int, _ column) string { return strings.Repeat("-", widths[i]) }),
+	}
+	for _, world := range f.Worlds {
+		lines = append(lines, line(func(_ int, c column) string { return cell(c, &world) }))
+	}
+
+	for _, l := range lines {
+		if _, err := fmt.Fprintln(w, l); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Read loads a sector file, telling the layouts apart by their header.
+func Read(r io.Reader) (File, error) {
+	var f File
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case strings.HasPrefix(line, "#"):
+			f.Comments = append(f.Comments, line[1:])
+		case strings.TrimSpace(line) == "":
+		default:
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return f, err
+	}
+
+	if len(lines) == 0 {
+		return f, errors.New("no sector data found")
+	}
+
+	var err error
+	switch {
+	case strings.Contains(lines[0], "\t"):
+		f.Format = Tab
+		f.Worlds, err = readTab(lines)
+	case len(lines) > 1 && strings.HasPrefix(lines[1], "----"):
+		f.Format = Column
+		f.Worlds, err = readColumns(lines)
+	case legacyLine.MatchString(lines[0]):
+		f.Format = Legacy
+		f.Worlds, err = readLegacy(lines)
+	default:
+		err = errors.New("unrecognised sector file layout")
+	}
+
+	return f, err
+}
+
+// findColumn matches a header to a column by either of its names, so files
+// that use other column orders still load.
+func findColumn(header string) (column, bool) {
+	header = strings.TrimSpace(header)
+	for _, c := range columns {
+		if strings.EqualFold(header, c.tab) || strings.EqualFold(header, c.short) {
+			return c, true
+		}
+	}
+	return column{}, false
+}
+
+func readTab(lines []string) ([]World, error) {
+	header := strings.Split(lines[0], "\t")
+	found := make([]*column, len(header))
+	for i, h := range header {
+		if c, ok := findColumn(h); ok {
+			found[i] = &c
+		}
+	}
+
+	var worlds []World
+	for n, line := range lines[1:] {
+		var world World
+		for i, value := range strings.Split(line, "\t") {
+			if i >= len(found) {
+				return nil, fmt.Errorf("line %d: more values than columns", n+2)
+			}
+			if found[i] != nil {
+				*found[i].get(&world) = strings.TrimSpace(value)
+			}
+		}
+		worlds = append(worlds, world)
+	}
+
+	return worlds, nil
+}
+
+type span struct {
+	start, end int
+	column     *column
+}
+
+func readColumns(lines []string) ([]World, error) {
+	var spans []span
+	dashes := lines[1]
+	for i := 0; i < len(dashes); {
+		if dashes[i] != '-' {
+			i++
+			continue
+		}
+		start := i
+		for i < len(dashes) && dashes[i] == '-' {
+			i++
+		}
+		s := span{start: start, end: i}
+		if c, ok := findColumn(slice(lines[0], s.start, s.end)); ok {
+			s.column = &c
+		}
+		spans = append(spans, s)
+	}
+
+	var worlds []World
+	for _, line := range lines[2:] {
+		var world World
+		for i, s := range spans {
+			end := s.end
+			if i == len(spans)-1 {
+				end = len(line)
+			}
+			if s.column == nil {
+				continue
+			}
+			value := strings.TrimSpace(slice(line, s.start, end))
+			if value == s.column.empty {
+				value = ""
+			}
+			*s.column.get(&world) = value
+		}
+		worlds = append(worlds, world)
+	}
+
+	return worlds, nil
+}
+
+// slice cuts line[start:end], tolerating short lines.
+func slice(line string, start int, end int) string {
+	if start >= len(line) {
+		return ""
+	}
+	return line[start:min(end, len(line))]
+}
+
+// legacyLine matches the name, hex and UWP that start every world in the
+// classic SEC layout, which has no header.
+var legacyLine = regexp.MustCompile(`^(.*?)\s*(\d{4})\s+([A-HXY?][0-9A-Z?]{6}-[0-9A-Z?])(.*)$`)
+
+// legacyTail matches the PBG, allegiance and stellar data that end a world
+// in the classic SEC layout.
+var legacyTail = regexp.MustCompile(`(?:^|\s)(\d{3})\s+(\S{2,4})(?:\s+(.*?))?\s*$`)
+
+// legacyBases are the single letter base codes that stood for a pair of
+// bases before T5.
+var legacyBases = map[string]string{
+	"A": "NS",
+	"B": "NW",
+}
+
+func readLegacy(lines []string) ([]World, error) {
+	var worlds []World
+
+	for n, line := range lines {
+		head := legacyLine.FindStringSubmatch(line)
+		if head == nil {
+			return nil, fmt.Errorf("line %d: not a SEC world", n+1)
+		}
+
+		world := World{
+			Name: strings.TrimSpace(head[1]),
+			Hex:  head[2],
+			UWP:  head[3],
+		}
+
+		rest := head[4]
+		if tail := legacyTail.FindStringSubmatchIndex(rest); tail != nil {
+			world.PBG = rest[tail[2]:tail[3]]
+			world.Allegiance = rest[tail[4]:tail[5]]
+			if tail[6] >= 0 {
+				world.Stars = rest[tail[6]:tail[7]]
+			}
+			rest = rest[:tail[0]]
+		}
+
+		codes := strings.Fields(rest)
+		if len(codes) > 0 && len(codes[0]) == 1 {
+			world.Bases = codes[0]
+			if pair, ok := legacyBases[world.Bases]; ok {
+				world.Bases = pair
+			}
+			codes = codes[1:]
+		}
+		if last := len(codes) - 1; last >= 0 && (codes[last] == "A" || codes[last] == "R") {
+			world.Zone = codes[last]
+			codes = codes[:last]
+		}
+		world.Remarks = strings.Join(codes, " ")
+
+		worlds = append(worlds, world)
+	}
+
+	return worlds, nil
+}
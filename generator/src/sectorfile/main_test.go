@@ -0,0 +1,152 @@
+package sectorfile
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const columnFixture = `Hex  Name                 UWP       Remarks           {Ix}  (Ex)    [Cx]   N B Z PBG W  A    Stellar  
+---- -------------------- --------- ----------------- ----- ------- ------ - - - --- -- ---- ---------
+0407 Isolation            C7C2500-B Fl He Ni          { 0 } (C44-4) [1516] - - - 714 15 NaHu K1 V K8 V
+1108 Aldo                 B200623-A Na Ni Va          { 1 } (B55-2) [3727] - K - 412 13 NaHu M1 V     
+1210 Inast                A663669-8 Ni Ri Da Mr(HoPA) { 0 } (B54+1) [7669] - K A 712 13 NaHu F1 V M9 V
+`
+
+func TestReadColumns(t *testing.T) {
+	f, err := Read(strings.NewReader(columnFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(f.Worlds) != 3 {
+		t.Fatalf("expected 3 worlds, got %d", len(f.Worlds))
+	}
+
+	expected := World{
+		Hex:        "1210",
+		Name:       "Inast",
+		UWP:        "A663669-8",
+		Remarks:    "Ni Ri Da Mr(HoPA)",
+		Ix:         "{ 0 }",
+		Ex:         "(B54+1)",
+		Cx:         "[7669]",
+		Bases:      "K",
+		Zone:       "A",
+		PBG:        "712",
+		W:          "13",
+		Allegiance: "NaHu",
+		Stars:      "F1 V M9 V",
+	}
+	if f.Worlds[2] != expected {
+		t.Fatalf("expected %+v, got %+v", expected, f.Worlds[2])
+	}
+}
+
+func TestColumnRoundTrip(t *testing.T) {
+	f, err := Read(strings.NewReader(columnFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := WriteColumns(&out, f); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != columnFixture {
+		t.Fatalf("round trip changed the file:\n%s", out.String())
+	}
+}
+
+func TestTabRoundTrip(t *testing.T) {
+	f, err := Read(strings.NewReader(columnFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Comments = []string{" Seed: 42"}
+
+	var out bytes.Buffer
+	if err := WriteTab(&out, f); err != nil {
+		t.Fatal(err)
+	}
+
+	header := "# Seed: 42\nHex\tName\tUWP\tRemarks\t{Ix}\t(Ex)\t[Cx]\tNobility\tBases\tZone\tPBG\tW\tAllegiance\tStars\n"
+	if !strings.HasPrefix(out.String(), header) {
+		t.Fatalf("unexpected header:\n%s", out.String())
+	}
+
+	again, err := Read(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(f.Comments, again.Comments) || !reflect.DeepEqual(f.Worlds, again.Worlds) {
+		t.Fatalf("round trip changed the file:\n%+v\n%+v", f, again)
+	}
+}
+
+const legacyFixture = `# Spinward Marches
+Regina        1910 A788899-C  A Ri Cp             703 Im M2 V M8 D
+Efate         1705 A646930-D  B Hi In           A 514 Im M0 V
+Pixie         1711 A100300-D    Lo Va             104 Im M6 V
+`
+
+func TestReadLegacy(t *testing.T) {
+	f, err := Read(strings.NewReader(legacyFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Format != Legacy || len(f.Worlds) != 3 {
+		t.Fatalf("expected 3 legacy worlds, got %d %s worlds", len(f.Worlds), f.Format)
+	}
+
+	expected := []World{
+		{Hex: "1910", Name: "Regina", UWP: "A788899-C", Bases: "NS", Remarks: "Ri Cp", PBG: "703", Allegiance: "Im", Stars: "M2 V M8 D"},
+		{Hex: "1705", Name: "Efate", UWP: "A646930-D", Bases: "NW", Remarks: "Hi In", Zone: "A", PBG: "514", Allegiance: "Im", Stars: "M0 V"},
+		{Hex: "1711", Name: "Pixie", UWP: "A100300-D", Remarks: "Lo Va", PBG: "104", Allegiance: "Im", Stars: "M6 V"},
+	}
+	if !reflect.DeepEqual(expected, f.Worlds) {
+		t.Fatalf("expected %+v, got %+v", expected, f.Worlds)
+	}
+}
+
+const metadataFixture = `<?xml version="1.0"?>
+<Sector>
+	<Name>Galactic Commons</Name>
+	<Subsector Index="A">Darksky</Subsector>
+	<Subsector Index="B">Atoon</Subsector>
+	<Allegiances>
+		<Allegiance Code="Gc">The Galactic Commons</Allegiance>
+	</Allegiances>
+	<Routes>
+		<Route Start="1204" End="1403" Type="Trade" Allegiance="Gc" Color="#00FF00"></Route>
+	</Routes>
+	<Borders>
+		<Border Allegiance="Gc" LabelPosition="0829" WrapLabel="true">2409 2509 2508 2409</Border>
+	</Borders>
+</Sector>
+`
+
+func TestMetadataRoundTrip(t *testing.T) {
+	m, err := ReadMetadata(strings.NewReader(metadataFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(m.Subsectors) != 2 || m.Subsectors[1].Name != "Atoon" {
+		t.Fatalf("subsectors read wrongly: %+v", m.Subsectors)
+	}
+	if hexes := m.Borders[0].Hexes(); len(hexes) != 4 || hexes[3] != "2409" {
+		t.Fatalf("border read wrongly: %v", hexes)
+	}
+
+	var out bytes.Buffer
+	if err := WriteMetadata(&out, m); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != metadataFixture {
+		t.Fatalf("round trip changed the metadata:\n%s", out.String())
+	}
+}
@@ -0,0 +1,150 @@
+package sectorfile
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// Metadata is the XML that Traveller Map reads alongside custom sector
+// data: subsector names, allegiances, routes and borders.
+type Metadata struct {
+	Name        string
+	Subsectors  []Subsector
+	Allegiances []Allegiance
+	Routes      []Route
+	Borders     []Border
+	Stylesheet  string
+}
+
+// metadataXML is the shape of the file on disk. The lists are pointers so
+// that empty ones are left out rather than written as empty elements.
+type metadataXML struct {
+	XMLName     xml.Name    `xml:"Sector"`
+	Name        string      `xml:"Name"`
+	Subsectors  []Subsector `xml:"Subsector"`
+	Allegiances *struct {
+		List []Allegiance `xml:"Allegiance"`
+	} `xml:"Allegiances"`
+	Routes *struct {
+		List []Route `xml:"Route"`
+	} `xml:"Routes"`
+	Borders *struct {
+		List []Border `xml:"Border"`
+	} `xml:"Borders"`
+	Stylesheet string `xml:"Stylesheet,omitempty"`
+}
+
+// Subsector names one of the sixteen subsectors, A to P.
+type Subsector struct {
+	Index string `xml:"Index,attr"`
+	Name  string `xml:",chardata"`
+}
+
+type Allegiance struct {
+	Code string `xml:"Code,attr"`
+	Name string `xml:",chardata"`
+}
+
+// Route joins two hexes in the sector.
+type Route struct {
+	Start      string `xml:"Start,attr"`
+	End        string `xml:"End,attr"`
+	Type       string `xml:"Type,attr,omitempty"`
+	Allegiance string `xml:"Allegiance,attr,omitempty"`
+	Color      string `xml:"Color,attr,omitempty"`
+}
+
+// Border outlines the hexes held by an allegiance. Path lists the hexes in
+// order, separated by whitespace.
+type Border struct {
+	Allegiance    string `xml:"Allegiance,attr"`
+	LabelPosition string `xml:"LabelPosition,attr,omitempty"`
+	WrapLabel     bool   `xml:"WrapLabel,attr,omitempty"`
+	Path          string `xml:",chardata"`
+}
+
+// Hexes splits the border path into hexes.
+func (b Border) Hexes() []string {
+	return strings.Fields(b.Path)
+}
+
+// NewBorder joins the hexes of a border into a path.
+func NewBorder(allegiance string, hexes []string) Border {
+	return Border{
+		Allegiance: allegiance,
+		Path:       strings.Join(hexes, " "),
+	}
+}
+
+// SubsectorIndex is the letter of the nth subsector, counting from zero.
+func SubsectorIndex(n int) string {
+	return string(rune('A' + n))
+}
+
+// WriteMetadata writes the metadata as indented XML.
+func WriteMetadata(w io.Writer, m Metadata) error {
+	if _, err := io.WriteString(w, "<?xml version=\"1.0\"?>\n"); err != nil {
+		return err
+	}
+
+	out := metadataXML{
+		Name:       m.Name,
+		Subsectors: m.Subsectors,
+		Stylesheet: m.Stylesheet,
+	}
+	if len(m.Allegiances) > 0 {
+		out.Allegiances = &struct {
+			List []Allegiance `xml:"Allegiance"`
+		}{m.Allegiances}
+	}
+	if len(m.Routes) > 0 {
+		out.Routes = &struct {
+			List []Route `xml:"Route"`
+		}{m.Routes}
+	}
+	if len(m.Borders) > 0 {
+		out.Borders = &struct {
+			List []Border `xml:"Border"`
+		}{m.Borders}
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ReadMetadata loads sector metadata XML.
+func ReadMetadata(r io.Reader) (Metadata, error) {
+	var in metadataXML
+	if err := xml.NewDecoder(r).Decode(&in); err != nil {
+		return Metadata{}, err
+	}
+
+	m := Metadata{
+		Name:       strings.TrimSpace(in.Name),
+		Subsectors: in.Subsectors,
+		Stylesheet: strings.TrimSpace(in.Stylesheet),
+	}
+	if in.Allegiances != nil {
+		m.Allegiances = in.Allegiances.List
+	}
+	if in.Routes != nil {
+		m.Routes = in.Routes.List
+	}
+	if in.Borders != nil {
+		m.Borders = in.Borders.List
+	}
+
+	// Hand written paths wrap over several lines; keep them on one.
+	for i, b := range m.Borders {
+		m.Borders[i].Path = strings.Join(b.Hexes(), " ")
+	}
+
+	return m, nil
+}
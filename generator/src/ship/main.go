@@ -0,0 +1,363 @@
+package ship
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+func newSeed() int64 {
+	return time.Now().UnixNano() % 1_000_000_000
+}
+
+// between rolls a whole number from min to max inclusive.
+func between(rng *rand.Rand, min, max int) int {
+	return rng.Intn(max-min+1) + min
+}
+
+// Performance is what the nav computer needs to plan a flight. The JSON keys
+// match the fields of its ShipDetail.
+type Performance struct {
+	MRating float64 `json:"mRating"`
+	JDrive  int     `json:"jdrive"`
+}
+
+func (p Performance) String() string {
+	return fmt.Sprintf("Jump-%d Thrust-%g", p.JDrive, p.MRating)
+}
+
+// Component is one line of a design: something that takes up space in the
+// hull and costs money.
+type Component struct {
+	Name string  `json:"name"`
+	Tons float64 `json:"tons"`
+	Cost float64 `json:"cost"` // MCr
+}
+
+type CrewPosition struct {
+	Position string `json:"position"`
+	Count    int    `json:"count"`
+}
+
+// Ship is a finished design.
+type Ship struct {
+	Seed        int64          `json:"seed"`
+	Role        Role           `json:"role"`
+	Hull        int            `json:"hull"`
+	Performance Performance    `json:"performance"`
+	FuelJumps   int            `json:"fuelJumps"`
+	Components  []Component    `json:"components"`
+	Cargo       float64        `json:"cargo"`
+	Crew        []CrewPosition `json:"crew"`
+	Passengers  int            `json:"passengers"`
+	LowBerths   int            `json:"lowBerths"`
+	Cost        float64        `json:"cost"` // MCr
+}
+
+type Role string
+
+const (
+	FreeTrader     Role = "Free Trader"
+	Scout          Role = "Scout"
+	PatrolCorvette Role = "Patrol Corvette"
+)
+
+var Roles = []Role{FreeTrader, Scout, PatrolCorvette}
+
+// ParseRole accepts a role by name, ignoring case, spaces and dashes.
+func ParseRole(name string) (Role, error) {
+	simplify := strings.NewReplacer(" ", "", "-", "", "_", "")
+	for _, r := range Roles {
+		if strings.EqualFold(simplify.Replace(string(r)), simplify.Replace(name)) {
+			return r, nil
+		}
+	}
+	return "", fmt.Errorf("unknown role %q: expected free-trader, scout or patrol-corvette", name)
+}
+
+type weapon struct {
+	name  string
+	cost  float64
+	power int
+}
+
+var (
+	pulseLaser  = weapon{"Pulse Laser", 1, 4}
+	beamLaser   = weapon{"Beam Laser", 0.5, 4}
+	missileRack = weapon{"Missile Rack", 0.75, 0}
+	sandcaster  = weapon{"Sandcaster", 0.25, 0}
+)
+
+// template is the range a typical ship of a role is rolled from.
+type template struct {
+	hulls      []int
+	jump       [2]int
+	thrust     [2]int
+	fuelJumps  int
+	passengers [2]int
+	lowBerths  [2]int
+	troops     [2]int
+	armed      float64 // fraction of hardpoints fitted with turrets
+	weapons    []weapon
+}
+
+var templates = map[Role]template{
+	FreeTrader: {
+		hulls:      []int{200, 200, 300, 400},
+		jump:       [2]int{1, 2},
+		thrust:     [2]int{1, 1},
+		fuelJumps:  1,
+		passengers: [2]int{4, 8},
+		lowBerths:  [2]int{0, 20},
+		armed:      0.5,
+		weapons:    []weapon{pulseLaser, beamLaser, sandcaster},
+	},
+	Scout: {
+		hulls:     []int{100},
+		jump:      [2]int{2, 2},
+		thrust:    [2]int{2, 2},
+		fuelJumps: 2,
+		armed:     1,
+		weapons:   []weapon{pulseLaser, beamLaser},
+	},
+	PatrolCorvette: {
+		hulls:     []int{400},
+		jump:      [2]int{3, 4},
+		thrust:    [2]int{4, 5},
+		fuelJumps: 1,
+		troops:    [2]int{4, 8},
+		armed:     1,
+		weapons:   []weapon{pulseLaser, beamLaser, missileRack, missileRack, sandcaster},
+	},
+}
+
+// Options control how a ship is rolled.
+type Options struct {
+	Seed int64 // zero picks a random seed
+	Role Role  // empty rolls one
+}
+
+// Roll designs a typical ship for a role.
+func Roll(opts Options) Ship {
+	if opts.Seed == 0 {
+		opts.Seed = newSeed()
+	}
+	// Each ship rolls from its own source, so a ship can be rolled again
+	// from its seed whatever else is rolling.
+	rng := rand.New(rand.NewSource(opts.Seed))
+
+	role := opts.Role
+	if role == "" {
+		role = Roles[rng.Intn(len(Roles))]
+	}
+
+	t := templates[role]
+	hull := t.hulls[rng.Intn(len(t.hulls))]
+	hardpoints := hull / 100
+
+	var turrets []weapon
+	for i := 0; i < int(math.Round(float64(hardpoints)*t.armed)); i++ {
+		turrets = append(turrets, t.weapons[rng.Intn(len(t.weapons))])
+	}
+
+	s := design(role, hull, Performance{
+		MRating: float64(between(rng, t.thrust[0], t.thrust[1])),
+		JDrive:  between(rng, t.jump[0], t.jump[1]),
+	}, t.fuelJumps, between(rng, t.passengers[0], t.passengers[1]), between(rng, t.lowBerths[0], t.lowBerths[1]), between(rng, t.troops[0], t.troops[1]), turrets)
+	s.Seed = opts.Seed
+
+	return s
+}
+
+// design lays out the hull, sizing the drives, power plant, fuel, bridge and
+// quarters. Whatever space is left over is cargo.
+func design(role Role, hull int, p Performance, fuelJumps, passengers, lowBerths, troops int, turrets []weapon) Ship {
+	tons := float64(hull)
+	s := Ship{
+		Role:        role,
+		Hull:        hull,
+		Performance: p,
+		FuelJumps:   fuelJumps,
+		Passengers:  passengers,
+		LowBerths:   lowBerths,
+	}
+
+	add := func(name string, tons, cost float64) {
+		s.Components = append(s.Components, Component{name, tons, cost})
+	}
+
+	add("Hull", 0, tons*0.05)
+
+	var jumpTons float64
+	if p.JDrive > 0 {
+		jumpTons = tons*0.025*float64(p.JDrive) + 5
+		add(fmt.Sprintf("Jump Drive (J-%d)", p.JDrive), jumpTons, jumpTons*1.5)
+	}
+
+	thrustTons := tons * 0.01 * p.MRating
+	add(fmt.Sprintf("Manoeuvre Drive (Thrust-%g)", p.MRating), thrustTons, thrustTons*2)
+
+	weaponPower := 0
+	for _, w := range turrets {
+		weaponPower += w.power
+	}
+	power := tons*0.2 + tons*0.1*p.MRating + tons*0.1*float64(p.JDrive) + float64(weaponPower)
+	plantTons := math.Ceil(power / 15)
+	add("Fusion Power Plant", plantTons, plantTons)
+
+	jumpFuel := tons * 0.1 * float64(p.JDrive) * float64(fuelJumps)
+	plantFuel := math.Ceil(plantTons * 0.1 * 3)
+	add(fmt.Sprintf("Fuel (%s, 12 weeks)", plural(fuelJumps, "jump")), jumpFuel+plantFuel, 0)
+
+	add("Bridge", bridgeTons(hull), math.Max(0.5, tons/100*0.5))
+
+	crew := crewFor(hull, p, jumpTons+thrustTons+plantTons, len(turrets), passengers, lowBerths, troops)
+	s.Crew = crew
+
+	staterooms := passengers + troops
+	for _, c := range crew {
+		staterooms += c.Count
+	}
+	add(fmt.Sprintf("Staterooms (%d)", staterooms), float64(staterooms)*4, float64(staterooms)*0.5)
+
+	if lowBerths > 0 {
+		add(fmt.Sprintf("Low Berths (%d)", lowBerths), float64(lowBerths)*0.5, float64(lowBerths)*0.05)
+	}
+
+	for _, w := range turrets {
+		add("Turret: "+w.name, 1, 0.2+w.cost)
+	}
+
+	s.Cargo = tons
+	for _, c := range s.Components {
+		s.Cargo -= c.Tons
+		s.Cost += c.Cost
+	}
+
+	return s
+}
+
+func bridgeTons(hull int) float64 {
+	switch {
+	case hull <= 50:
+		return 3
+	case hull < 100:
+		return 6
+	case hull <= 200:
+		return 10
+	case hull <= 1000:
+		return 20
+	}
+	return 40
+}
+
+// crewFor staffs the ship: one engineer for every 35 tons of drives and
+// power plant, a gunner per turret, a steward for every eight passengers and
+// a medic once there are more than a dozen people aboard or anyone in a low
+// berth.
+func crewFor(hull int, p Performance, engineering float64, turrets, passengers, lowBerths, troops int) []CrewPosition {
+	crew := []CrewPosition{{"Pilot", 1}}
+	if p.JDrive > 0 {
+		crew = append(crew, CrewPosition{"Astrogator", 1})
+	}
+	crew = append(crew, CrewPosition{"Engineer", int(math.Ceil(engineering / 35))})
+
+	if turrets > 0 {
+		crew = append(crew, CrewPosition{"Gunner", turrets})
+	}
+	if passengers > 0 {
+		crew = append(crew, CrewPosition{"Steward", int(math.Ceil(float64(passengers) / 8))})
+	}
+	if troops > 0 {
+		crew = append(crew, CrewPosition{"Marine", troops})
+	}
+
+	aboard := passengers
+	for _, c := range crew {
+		aboard += c.Count
+	}
+	if aboard > 12 || lowBerths > 0 || hull >= 400 {
+		crew = append(crew, CrewPosition{"Medic", 1})
+	}
+
+	return crew
+}
+
+func (s Ship) crewCount() int {
+	var n int
+	for _, c := range s.Crew {
+		n += c.Count
+	}
+	return n
+}
+
+type Format string
+
+const (
+	Text Format = "text"
+	JSON        = "json"
+)
+
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(name); f {
+	case Text, JSON:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown format %q: expected text or json", name)
+}
+
+// Generate rolls a ship and writes it to w without any user interface.
+func Generate(w io.Writer, opts Options, format Format) error {
+	s := Roll(opts)
+
+	if format == JSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	}
+
+	_, err := io.WriteString(w, s.Sheet())
+	return err
+}
+
+// Sheet lays the design out as a table of components.
+func (s Ship) Sheet() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s, %d tons (seed %d)\n", s.Role, s.Hull, s.Seed)
+	fmt.Fprintf(&b, "%s, mRating %g, jdrive %d\n\n", s.Performance, s.Performance.MRating, s.Performance.JDrive)
+
+	fmt.Fprintf(&b, "%-32s %7s %8s\n", "Component", "Tons", "MCr")
+	for _, c := range s.Components {
+		fmt.Fprintf(&b, "%-32s %7s %8.2f\n", c.Name, formatTons(c.Tons), c.Cost)
+	}
+	fmt.Fprintf(&b, "%-32s %7s\n", "Cargo", formatTons(s.Cargo))
+	fmt.Fprintf(&b, "%-32s %7d %8.2f\n\n", "Total", s.Hull, s.Cost)
+
+	var crew []string
+	for _, c := range s.Crew {
+		crew = append(crew, fmt.Sprintf("%s %d", c.Position, c.Count))
+	}
+	fmt.Fprintf(&b, "Crew (%d): %s\n", s.crewCount(), strings.Join(crew, ", "))
+	fmt.Fprintf(&b, "Passengers: %d high, %d low\n", s.Passengers, s.LowBerths)
+
+	return b.String()
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func formatTons(tons float64) string {
+	if tons == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%g", math.Round(tons*10)/10)
+}
@@ -0,0 +1,47 @@
+package ship
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRolledShipsFitTheirHulls(t *testing.T) {
+	for _, role := range Roles {
+		for seed := int64(1); seed <= 200; seed++ {
+			s := Roll(Options{Seed: seed, Role: role})
+			if s.Cargo < 0 {
+				t.Fatalf("%s seed %d is overloaded by %g tons", role, seed, -s.Cargo)
+			}
+			if s.Performance.JDrive < 1 || s.Performance.MRating < 1 {
+				t.Fatalf("%s seed %d cannot fly: %s", role, seed, s.Performance)
+			}
+		}
+	}
+}
+
+func TestRollIsReproducible(t *testing.T) {
+	a := Roll(Options{Seed: 1977})
+	b := Roll(Options{Seed: 1977})
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("same seed rolled different ships:\n%s\n%s", a.Sheet(), b.Sheet())
+	}
+}
+
+func TestScoutDesign(t *testing.T) {
+	s := design(Scout, 100, Performance{MRating: 2, JDrive: 2}, 1, 0, 0, 0, nil)
+
+	// 10 jump drive, 2 manoeuvre, 4 power plant, 22 fuel, 10 bridge and
+	// three 4 ton staterooms leaves 40 tons of cargo.
+	if s.Cargo != 40 {
+		t.Fatalf("expected 40 tons of cargo, got %g\n%s", s.Cargo, s.Sheet())
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	if r, err := ParseRole("patrol-corvette"); err != nil || r != PatrolCorvette {
+		t.Fatalf("expected %s, got %q %v", PatrolCorvette, r, err)
+	}
+	if _, err := ParseRole("dreadnought"); err == nil {
+		t.Fatal("expected an error for an unknown role")
+	}
+}
@@ -0,0 +1,93 @@
+package ship
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// New creates the ship generator. It starts with the role in opts, or the
+// first role when none is given.
+func New(opts Options) tea.Model {
+	if opts.Role == "" {
+		opts.Role = Roles[0]
+	}
+	if opts.Seed == 0 {
+		opts.Seed = newSeed()
+	}
+
+	return model{
+		help: help.New(),
+		opts: opts,
+		ship: Roll(opts),
+	}
+}
+
+type model struct {
+	help help.Model
+	opts Options
+	ship Ship
+}
+
+type keyMap struct {
+	Prev   key.Binding
+	Next   key.Binding
+	Reroll key.Binding
+}
+
+func (k keyMap) shortHelp() []key.Binding {
+	return []key.Binding{k.Prev, k.Next, k.Reroll}
+}
+
+var keys = keyMap{
+	Prev: key.NewBinding(
+		key.WithKeys("h", "left"),
+		key.WithHelp("←/h", "prev role"),
+	),
+	Next: key.NewBinding(
+		key.WithKeys("l", "right"),
+		key.WithHelp("→/l", "next role"),
+	),
+	Reroll: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "reroll"),
+	),
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Prev):
+			m.opts.Role = m.nextRole(-1)
+		case key.Matches(msg, keys.Next):
+			m.opts.Role = m.nextRole(1)
+		case key.Matches(msg, keys.Reroll):
+			m.opts.Seed = newSeed()
+		default:
+			return m, nil
+		}
+		m.ship = Roll(m.opts)
+	}
+
+	return m, nil
+}
+
+func (m model) nextRole(step int) Role {
+	for i, r := range Roles {
+		if r == m.opts.Role {
+			return Roles[(i+step+len(Roles))%len(Roles)]
+		}
+	}
+	return Roles[0]
+}
+
+func (m model) View() string {
+	return fmt.Sprintf("\n\n%s\n %s", m.ship.Sheet(), m.help.ShortHelpView(keys.shortHelp()))
+}
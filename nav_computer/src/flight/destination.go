@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"nav_computer/travellermap"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/violetexistence/traveller/shared/hexmap"
 )
 
 type screenMode uint
@@ -16,6 +18,7 @@ const (
 	idle screenMode = iota
 	searching
 	listMode
+	mapMode
 )
 
 type DestinationScreen struct {
@@ -28,6 +31,7 @@ type DestinationScreen struct {
 	spinner        spinner.Model
 	list           list.Model
 	mode           screenMode
+	subsectorMap   string
 }
 
 func (m DestinationScreen) Init() tea.Cmd {
@@ -46,11 +50,36 @@ func (m DestinationScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.mode = listMode
 		m.SetWorldsInRange(msg.worlds)
 		m.list = createList(m)
+	case subsectorMapMsg:
+		if msg.err != nil {
+			// Back to the list, which says what went wrong.
+			m.mode = listMode
+			cmds = append(cmds, m.list.NewStatusMessage(msg.err.Error()))
+			break
+		}
+		m.mode = mapMode
+		m.subsectorMap = msg.drawn
 	case tea.KeyMsg:
 		if m.list.FilterState() == list.Filtering {
 			break
 		}
 
+		if m.mode == mapMode {
+			// Any key goes back to the list.
+			m.mode = listMode
+			return m, nil
+		}
+
+		if m.mode == listMode && msg.String() == "m" {
+			selection, ok := m.list.SelectedItem().(DestinationWorldItem)
+			if !ok {
+				// Nothing to map when the filter has left no worlds.
+				return m, nil
+			}
+			m.mode = searching
+			return m, tea.Batch(m.spinner.Tick, fetchSubsectorMap(selection.world))
+		}
+
 		switch msg.Type {
 		case tea.KeyEsc, tea.KeyCtrlC:
 			return m, transition(PreviousMsg)
@@ -86,6 +115,8 @@ func (m DestinationScreen) View() string {
 		return m.spinner.View() + " Searching..."
 	case listMode:
 		return m.list.View()
+	case mapMode:
+		return m.subsectorMap
 	default:
 		return ""
 	}
@@ -119,21 +150,44 @@ func fetchWorldsInRange(m DestinationScreen) tea.Cmd {
 	}
 }
 
-func (m DestinationScreen) SetWorldsInRange(worlds []travellermap.WorldDetail) {
+// fetchSubsectorMap draws the subsector around a world.
+func fetchSubsectorMap(world travellermap.WorldDetail) tea.Cmd {
+	return func() tea.Msg {
+		index := hexmap.SubsectorOf(world.Hex)
+		worlds, err := travellermap.FetchSubsector(world.Sector, index)
+		if err != nil {
+			return subsectorMapMsg{err: err}
+		}
+
+		var onMap []hexmap.World
+		for _, w := range worlds {
+			onMap = append(onMap, w.MapWorld())
+		}
+
+		title := fmt.Sprintf("%s, subsector %c: %s", world.Sector, 'A'+index, world.SubsectorName)
+		return subsectorMapMsg{
+			drawn: title + "\n" + hexmap.Subsector(index, onMap) + "\n" + hexmap.Legend,
+		}
+	}
+}
+
+func (m *DestinationScreen) SetWorldsInRange(worlds []travellermap.WorldDetail) {
 	var minusOrigin []travellermap.WorldDetail
 
-	// inspect the worlds given and remove the starting world
-	for i := range worlds {
-		w := worlds[i]
-		if w.Sector == m.startingSector && w.Hex == m.startingHex {
-			minusOrigin = append(worlds[:i], worlds[i+1:]...)
-			break
+	// keep every world given but the starting world
+	for _, w := range worlds {
+		if w.Sector != m.startingSector || w.Hex != m.startingHex {
+			minusOrigin = append(minusOrigin, w)
 		}
 	}
 	m.worldsInRange = minusOrigin
 }
 
 type startMsg struct{}
+type subsectorMapMsg struct {
+	drawn string
+	err   error
+}
 type worldsInRangeMsg struct {
 	worlds []travellermap.WorldDetail
 }
@@ -158,6 +212,9 @@ func createList(m DestinationScreen) list.Model {
 
 	list := list.New(items, list.NewDefaultDelegate(), 40, 40)
 	list.Title = fmt.Sprintf("Worlds within jump %d", m.jump)
+	list.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "subsector map"))}
+	}
 
 	return list
 }
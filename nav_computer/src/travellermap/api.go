@@ -7,6 +7,9 @@ import (
 	"io"
 	"log"
 	"net/http"
+
+	"github.com/violetexistence/traveller/shared/hexmap"
+	"github.com/violetexistence/traveller/shared/uwp"
 )
 
 func Search(query string) (*SearchResults, error) {
@@ -80,6 +83,36 @@ func FetchNearbyWorlds(sector string, hex string, within int) ([]WorldDetail, er
 	return nil, errors.New(fmt.Sprintf("No results on travellermap for %s/%s", sector, hex))
 }
 
+// FetchSubsector finds every world in one subsector, 0 to 15 for A to P, by
+// searching out from its middle hex far enough to reach the corners.
+func FetchSubsector(sector string, index int) ([]WorldDetail, error) {
+	hex := fmt.Sprintf("%02d%02d", index%4*8+4, index/4*10+5)
+	worlds, err := FetchNearbyWorlds(sector, hex, 8)
+	if err != nil {
+		return nil, err
+	}
+
+	var inSubsector []WorldDetail
+	for _, w := range worlds {
+		if w.Sector == sector && hexmap.SubsectorOf(w.Hex) == index {
+			inSubsector = append(inSubsector, w)
+		}
+	}
+	return inSubsector, nil
+}
+
+// MapWorld is what the subsector map shows of a world.
+func (w WorldDetail) MapWorld() hexmap.World {
+	return hexmap.World{
+		Hex:      w.Hex,
+		Name:     w.Name,
+		Starport: w.Profile().Starport,
+		GasGiant: len(w.Pbg) == 3 && w.Pbg[2] != '0',
+		Bases:    w.Bases,
+		Zone:     w.Zone,
+	}
+}
+
 func FetchWorldDetail(sector string, hex string) (*WorldDetail, error) {
 	worlds, err := FetchNearbyWorlds(sector, hex, 0)
 	if err == nil {
@@ -93,6 +126,22 @@ func FetchWorldDetail(sector string, hex string) (*WorldDetail, error) {
 	}
 }
 
+// Profile parses the world's UWP. One travellermap has that does not parse,
+// such as an unsurveyed "???????-?", comes back with every digit unknown
+// rather than losing the rest of the jump list with it.
+func (w WorldDetail) Profile() uwp.UWP {
+	profile, err := uwp.Parse(w.Uwp)
+	if err == nil {
+		return profile
+	}
+
+	unknown := uwp.UWP{Starport: '?'}
+	for _, f := range uwp.Fields[1:] {
+		unknown = unknown.With(f, uwp.Unknown)
+	}
+	return unknown
+}
+
 type WorldResults struct {
 	Worlds []WorldDetail `json:"Worlds"`
 }
@@ -266,8 +266,7 @@ func ComputeSpectralClass(world WorldDetail) string {
 }
 
 func ComputeWorldDiameter(world WorldDetail) string {
-	size, _ := strconv.ParseUint(string([]rune(world.Uwp)[1]), 10, 64)
-	km := 1600 * size
+	km := 1600 * max(world.Profile().Size, 0)
 	miles := float64(km) / 1.609
 	k := 0
 	for k = range planetary_diameter_map {
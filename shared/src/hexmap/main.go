@@ -0,0 +1,180 @@
+// Package hexmap draws a subsector as a grid of hexes in the terminal, in the
+// manner of the classic subsector map: eight columns by ten rows, odd columns
+// raised half a hex, each hex showing its number, bases, starport, a gas
+// giant marker and the world's name in the colour of its travel zone.
+package hexmap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// World is what the map needs to know about the world in a hex.
+type World struct {
+	Hex      string // sector hex, such as "0407"
+	Name     string
+	Starport byte
+	GasGiant bool
+	Bases    string
+	Zone     string // "", "A" or "R"
+}
+
+const (
+	columns = 8
+	rows    = 10
+
+	// Each hex is seven underscores across the top, so the columns step
+	// nine characters across and the rows four lines down.
+	hexWidth  = 9
+	hexHeight = 4
+	width     = columns*hexWidth + 2
+	height    = rows*hexHeight + hexHeight/2 + 1
+)
+
+var (
+	gridStyle     = lipgloss.NewStyle().Faint(true)
+	starportStyle = lipgloss.NewStyle().Bold(true)
+	zoneStyles    = map[string]lipgloss.Style{
+		"A": lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+		"R": lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
+	}
+)
+
+// Legend explains the marks on the map.
+const Legend = "• gas giant  N naval base  S scout base  amber and red names are zoned"
+
+// canvas is a grid of characters, each with the style it is drawn in.
+type canvas struct {
+	cells  [height][width]rune
+	styles [height][width]*lipgloss.Style
+}
+
+func (c *canvas) put(x, y int, text string, style *lipgloss.Style) {
+	for _, r := range text {
+		if x >= 0 && x < width && y >= 0 && y < height {
+			c.cells[y][x] = r
+			c.styles[y][x] = style
+		}
+		x++
+	}
+}
+
+func (c *canvas) String() string {
+	var lines []string
+	for y := range c.cells {
+		var line strings.Builder
+		for x := 0; x < width; {
+			// Render each run of one style together.
+			style, run := c.styles[y][x], ""
+			for ; x < width && c.styles[y][x] == style; x++ {
+				if c.cells[y][x] == 0 {
+					run += " "
+				} else {
+					run += string(c.cells[y][x])
+				}
+			}
+			if style == nil {
+				line.WriteString(run)
+			} else {
+				line.WriteString(style.Render(run))
+			}
+		}
+		lines = append(lines, strings.TrimRight(line.String(), " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Subsector draws subsector sub, 0 to 15 for A to P. Worlds in other
+// subsectors are left off.
+func Subsector(sub int, worlds []World) string {
+	var c canvas
+	left, top := sub%4*columns, sub/4*rows
+
+	byHex := map[string]World{}
+	for _, w := range worlds {
+		byHex[w.Hex] = w
+	}
+
+	for col := 0; col < columns; col++ {
+		for row := 0; row < rows; row++ {
+			x, y := col*hexWidth, row*hexHeight
+			if col%2 == 1 {
+				y += hexHeight / 2
+			}
+			drawHex(&c, x, y)
+
+			hex := fmt.Sprintf("%02d%02d", left+col+1, top+row+1)
+			c.put(x+3, y+1, hex, &gridStyle)
+			if w, ok := byHex[hex]; ok {
+				drawWorld(&c, x, y, w)
+			}
+		}
+	}
+
+	return c.String()
+}
+
+// drawHex outlines a hex whose top left corner is at x, y:
+//
+//	  _______
+//	 /       \
+//	/         \
+//	\         /
+//	 \_______/
+func drawHex(c *canvas, x, y int) {
+	c.put(x+2, y, "_______", &gridStyle)
+	c.put(x+1, y+1, "/", &gridStyle)
+	c.put(x+9, y+1, "\\", &gridStyle)
+	c.put(x, y+2, "/", &gridStyle)
+	c.put(x+10, y+2, "\\", &gridStyle)
+	c.put(x, y+3, "\\", &gridStyle)
+	c.put(x+10, y+3, "/", &gridStyle)
+	c.put(x+1, y+4, "\\_______/", &gridStyle)
+}
+
+// drawWorld fills in a hex: bases to the left of the starport, a gas giant
+// to its right, and the name underneath.
+func drawWorld(c *canvas, x, y int, w World) {
+	var text *lipgloss.Style
+	if style, ok := zoneStyles[w.Zone]; ok {
+		text = &style
+	}
+
+	starport := starportStyle
+	if text != nil {
+		starport = text.Copy().Bold(true)
+	}
+
+	bases := w.Bases
+	if len(bases) > 2 {
+		bases = bases[:2]
+	}
+	c.put(x+2, y+2, bases, text)
+	c.put(x+5, y+2, string(w.Starport), &starport)
+	if w.GasGiant {
+		c.put(x+7, y+2, "•", text)
+	}
+
+	name := []rune(w.Name)
+	if len(name) > 9 {
+		name = name[:9]
+	}
+	c.put(x+1+(9-len(name))/2, y+3, string(name), text)
+}
+
+// SubsectorOf is the subsector, 0 to 15, holding a sector hex such as
+// "0407", or -1 when it is not a hex.
+func SubsectorOf(hex string) int {
+	if len(hex) != 4 {
+		return -1
+	}
+	x, errX := strconv.Atoi(hex[:2])
+	y, errY := strconv.Atoi(hex[2:])
+	if errX != nil || errY != nil || x < 1 || x > 32 || y < 1 || y > 40 {
+		return -1
+	}
+	return (y-1)/rows*4 + (x-1)/columns
+}
@@ -0,0 +1,37 @@
+package hexmap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubsector(t *testing.T) {
+	worlds := []World{
+		{Hex: "1112", Name: "Isolation", Starport: 'C', GasGiant: true, Bases: "NS", Zone: "A"},
+		{Hex: "0913", Name: "Glee", Starport: 'B'},
+		{Hex: "0101", Name: "Elsewhere", Starport: 'A'},
+	}
+
+	drawn := Subsector(5, worlds)
+	lines := strings.Split(drawn, "\n")
+	if len(lines) != height {
+		t.Fatalf("expected %d lines, got %d", height, len(lines))
+	}
+
+	for _, expected := range []string{"0911", "1620", "Isolation", "NS", "•", "Glee"} {
+		if !strings.Contains(drawn, expected) {
+			t.Errorf("expected the map of F to show %q:\n%s", expected, drawn)
+		}
+	}
+	if strings.Contains(drawn, "Elsewhere") {
+		t.Error("a world in subsector A was drawn on the map of F")
+	}
+}
+
+func TestSubsectorOf(t *testing.T) {
+	for hex, expected := range map[string]int{"0101": 0, "0810": 0, "0911": 5, "3240": 15, "3300": -1, "abc": -1} {
+		if actual := SubsectorOf(hex); actual != expected {
+			t.Errorf("SubsectorOf(%s) = %d, expected %d", hex, actual, expected)
+		}
+	}
+}
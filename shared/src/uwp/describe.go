@@ -0,0 +1,197 @@
+package uwp
+
+import "fmt"
+
+var starportDescriptions = map[byte]string{
+	'A': "Excellent: refined fuel, shipyard (starships), overhaul",
+	'B': "Good: refined fuel, shipyard (spacecraft), overhaul",
+	'C': "Routine: unrefined fuel, shipyard (small craft), repairs",
+	'D': "Poor: unrefined fuel, limited repairs",
+	'E': "Frontier: marked landing site, no fuel or repairs",
+	'X': "None: no starport",
+	'F': "Good spaceport: minor repairs, unrefined fuel",
+	'G': "Poor spaceport: superficial repairs, unrefined fuel",
+	'H': "Primitive spaceport: no fuel or repairs",
+	'Y': "None: no spaceport",
+}
+
+func DescribeStarport(class byte) string {
+	if description, ok := starportDescriptions[class]; ok {
+		return description
+	}
+	return "Unknown"
+}
+
+func DescribeSize(size int) string {
+	switch {
+	case size < 0:
+		return "Unknown"
+	case size == 0:
+		return "Asteroid belt or planetoid"
+	case size == 1:
+		return "1,600 km, negligible gravity"
+	}
+	return fmt.Sprintf("%s km", thousands(size*1600))
+}
+
+func thousands(n int) string {
+	return fmt.Sprintf("%d,%03d", n/1000, n%1000)
+}
+
+var atmosphereDescriptions = []string{
+	"None: vacc suit required",
+	"Trace: vacc suit required",
+	"Very thin, tainted: respirator and filter required",
+	"Very thin: respirator required",
+	"Thin, tainted: filter mask required",
+	"Thin: breathable",
+	"Standard: breathable",
+	"Standard, tainted: filter mask required",
+	"Dense: breathable",
+	"Dense, tainted: filter mask required",
+	"Exotic: air supply required",
+	"Corrosive: vacc suit required",
+	"Insidious: protective suit required",
+	"Very dense: breathable only at altitude",
+	"Low: breathable only in lowlands",
+	"Unusual: conditions vary",
+}
+
+func DescribeAtmosphere(atmosphere int) string {
+	return describeFrom(atmosphereDescriptions, atmosphere)
+}
+
+var hydrographicsDescriptions = []string{
+	"Desert world: 0-5% surface water",
+	"Dry world: 6-15% surface water",
+	"A few small seas: 16-25% surface water",
+	"Small seas and oceans: 26-35% surface water",
+	"Wet world: 36-45% surface water",
+	"Large oceans: 46-55% surface water",
+	"Large oceans: 56-65% surface water",
+	"Earth-like: 66-75% surface water",
+	"Water world: 76-85% surface water",
+	"Only a few small islands: 86-95% surface water",
+	"Almost entirely water: 96-100% surface water",
+}
+
+func DescribeHydrographics(hydrographics int) string {
+	return describeFrom(hydrographicsDescriptions, hydrographics)
+}
+
+var populationDescriptions = []string{
+	"None",
+	"Tens",
+	"Hundreds",
+	"Thousands",
+	"Tens of thousands",
+	"Hundreds of thousands",
+	"Millions",
+	"Tens of millions",
+	"Hundreds of millions",
+	"Billions",
+	"Tens of billions",
+	"Hundreds of billions",
+	"Trillions",
+	"Tens of trillions",
+	"Hundreds of trillions",
+	"Quadrillions",
+}
+
+func DescribePopulation(population int) string {
+	return describeFrom(populationDescriptions, population)
+}
+
+var governmentDescriptions = []string{
+	"No government structure",
+	"Company or corporation",
+	"Participating democracy",
+	"Self-perpetuating oligarchy",
+	"Representative democracy",
+	"Feudal technocracy",
+	"Captive government",
+	"Balkanisation",
+	"Civil service bureaucracy",
+	"Impersonal bureaucracy",
+	"Charismatic dictator",
+	"Non-charismatic leader",
+	"Charismatic oligarchy",
+	"Religious dictatorship",
+	"Religious autocracy",
+	"Totalitarian oligarchy",
+}
+
+func DescribeGovernment(government int) string {
+	return describeFrom(governmentDescriptions, government)
+}
+
+// lawDescriptions list what each law level bans on top of the levels below.
+var lawDescriptions = []string{
+	"No restrictions",
+	"Bans poison gas, explosives, undetectable weapons and WMD",
+	"Bans portable energy and laser weapons",
+	"Bans military weapons",
+	"Bans light assault weapons and submachine guns",
+	"Bans personal concealable weapons",
+	"Bans all firearms except shotguns and stunners",
+	"Bans shotguns",
+	"Bans all bladed weapons and stunners",
+}
+
+func DescribeLaw(law int) string {
+	if law < 0 {
+		return "Unknown"
+	}
+	if law >= len(lawDescriptions) {
+		return "Bans any weapon outside the home"
+	}
+	return lawDescriptions[law]
+}
+
+func DescribeTechLevel(tech int) string {
+	switch {
+	case tech < 0:
+		return "Unknown"
+	case tech < 1:
+		return "Stone age"
+	case tech < 4:
+		return "Pre-industrial"
+	case tech < 7:
+		return "Industrial"
+	case tech < 10:
+		return "Pre-stellar"
+	case tech < 12:
+		return "Early stellar"
+	case tech < 15:
+		return "Average stellar"
+	}
+	return "High stellar"
+}
+
+func describeFrom(descriptions []string, value int) string {
+	if value < 0 || value >= len(descriptions) {
+		return "Unknown"
+	}
+	return descriptions[value]
+}
+
+// Describe spells out every field, one line each, such as
+// "Size 8: 12,800 km".
+func (u UWP) Describe() []string {
+	describers := map[Field]func(int) string{
+		Size:          DescribeSize,
+		Atmosphere:    DescribeAtmosphere,
+		Hydrographics: DescribeHydrographics,
+		Population:    DescribePopulation,
+		Government:    DescribeGovernment,
+		Law:           DescribeLaw,
+		TechLevel:     DescribeTechLevel,
+	}
+
+	lines := []string{fmt.Sprintf("%s %c: %s", Starport, u.Starport, DescribeStarport(u.Starport))}
+	for _, f := range Fields[1:] {
+		value := u.Get(f)
+		lines = append(lines, fmt.Sprintf("%s %s: %s", f, Encode(value), describers[f](value)))
+	}
+	return lines
+}
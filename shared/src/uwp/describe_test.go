@@ -0,0 +1,24 @@
+package uwp
+
+import "testing"
+
+func TestDescribeAtmosphere(t *testing.T) {
+	if actual := DescribeAtmosphere(6); actual != "Standard: breathable" {
+		t.Fatalf("unexpected description %q", actual)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	u, err := Parse("A788899-C")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := u.Describe()
+	if len(lines) != len(Fields) {
+		t.Fatalf("expected a line per field, got %v", lines)
+	}
+	if expected := "Law level 9: Bans any weapon outside the home"; lines[Law] != expected {
+		t.Errorf("expected %q, got %q", expected, lines[Law])
+	}
+}
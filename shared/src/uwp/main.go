@@ -0,0 +1,222 @@
+// Package uwp reads, writes and explains Universal World Profiles, such as
+// "A788899-C". Each digit is T5 extended hex, which runs 0-9 then A-Z
+// skipping I and O, so law levels reach J and tech levels go past F.
+package uwp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Digits are the extended hex digits in order of value.
+const Digits = "0123456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// Unknown stands for a digit written as "?".
+const Unknown = -1
+
+// Decode reads a single extended hex digit.
+func Decode(digit byte) (int, error) {
+	if i := strings.IndexByte(Digits, digit); i >= 0 {
+		return i, nil
+	}
+	return 0, fmt.Errorf("%q is not an eHex digit", digit)
+}
+
+// Encode writes a value as a single extended hex digit, or "?" when it
+// cannot be written as one.
+func Encode(value int) string {
+	if value < 0 || value >= len(Digits) {
+		return "?"
+	}
+	return Digits[value : value+1]
+}
+
+// Field is one of the eight parts of a UWP, in the order they are written.
+type Field int
+
+const (
+	Starport Field = iota
+	Size
+	Atmosphere
+	Hydrographics
+	Population
+	Government
+	Law
+	TechLevel
+)
+
+var fieldNames = []string{
+	"Starport",
+	"Size",
+	"Atmosphere",
+	"Hydrographics",
+	"Population",
+	"Government",
+	"Law level",
+	"Tech level",
+}
+
+func (f Field) String() string {
+	return fieldNames[f]
+}
+
+// Fields lists every field in order.
+var Fields = []Field{Starport, Size, Atmosphere, Hydrographics, Population, Government, Law, TechLevel}
+
+// ranges are the values each digit may take. T5 lets law run to J and tech
+// level to Z.
+var ranges = map[Field][2]int{
+	Size:          {0, 0xF},
+	Atmosphere:    {0, 0xF},
+	Hydrographics: {0, 0xA},
+	Population:    {0, 0xF},
+	Government:    {0, 0xF},
+	Law:           {0, 18},
+	TechLevel:     {0, 33},
+}
+
+// Range is the lowest and highest value a numeric field may take.
+func Range(f Field) (min int, max int) {
+	r := ranges[f]
+	return r[0], r[1]
+}
+
+// Starports are the classes a starport may be: A to E and X for a
+// mainworld, F, G, H and Y for the spaceports of other worlds.
+const Starports = "ABCDEXFGHY"
+
+// UWP is a parsed world profile. Any digit may be Unknown.
+type UWP struct {
+	Starport      byte
+	Size          int
+	Atmosphere    int
+	Hydrographics int
+	Population    int
+	Government    int
+	Law           int
+	TechLevel     int
+}
+
+// Parse reads a UWP such as "A788899-C" and checks every field is in range.
+func Parse(s string) (UWP, error) {
+	s = strings.TrimSpace(s)
+	if len(s) != 9 || s[7] != '-' {
+		return UWP{}, fmt.Errorf("%q is not a UWP: expected eight digits like A788899-C", s)
+	}
+
+	u := UWP{Starport: s[0]}
+	digits := s[1:7] + s[8:]
+	for i, f := range Fields[1:] {
+		value := Unknown
+		if digits[i] != '?' {
+			var err error
+			if value, err = Decode(digits[i]); err != nil {
+				return UWP{}, fmt.Errorf("%s in %q: %w", f, s, err)
+			}
+		}
+		u = u.With(f, value)
+	}
+
+	if err := u.Validate(); err != nil {
+		return UWP{}, fmt.Errorf("%q: %w", s, err)
+	}
+
+	return u, nil
+}
+
+// Validate checks the starport is a known class and every digit is in its
+// range.
+func (u UWP) Validate() error {
+	if u.Starport != '?' && strings.IndexByte(Starports, u.Starport) < 0 {
+		return fmt.Errorf("starport %q is not one of %s", u.Starport, Starports)
+	}
+
+	for _, f := range Fields[1:] {
+		value := u.Get(f)
+		min, max := Range(f)
+		if value != Unknown && (value < min || value > max) {
+			return fmt.Errorf("%s %s is out of range %s-%s", strings.ToLower(f.String()), Encode(value), Encode(min), Encode(max))
+		}
+	}
+
+	return nil
+}
+
+func (u UWP) String() string {
+	var b strings.Builder
+	b.WriteByte(u.Starport)
+	for _, f := range Fields[1:] {
+		if f == TechLevel {
+			b.WriteByte('-')
+		}
+		b.WriteString(Encode(u.Get(f)))
+	}
+	return b.String()
+}
+
+// Get is the value of a numeric field. For the starport it is the class
+// letter.
+func (u UWP) Get(f Field) int {
+	switch f {
+	case Starport:
+		return int(u.Starport)
+	case Size:
+		return u.Size
+	case Atmosphere:
+		return u.Atmosphere
+	case Hydrographics:
+		return u.Hydrographics
+	case Population:
+		return u.Population
+	case Government:
+		return u.Government
+	case Law:
+		return u.Law
+	case TechLevel:
+		return u.TechLevel
+	}
+	return Unknown
+}
+
+// With returns a copy of the UWP with one field replaced.
+func (u UWP) With(f Field, value int) UWP {
+	switch f {
+	case Starport:
+		u.Starport = byte(value)
+	case Size:
+		u.Size = value
+	case Atmosphere:
+		u.Atmosphere = value
+	case Hydrographics:
+		u.Hydrographics = value
+	case Population:
+		u.Population = value
+	case Government:
+		u.Government = value
+	case Law:
+		u.Law = value
+	case TechLevel:
+		u.TechLevel = value
+	}
+	return u
+}
+
+// MarshalText writes the UWP as text, so it can be used directly in JSON.
+func (u UWP) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText parses a UWP. An empty one is left as the zero UWP.
+func (u *UWP) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*u = UWP{}
+		return nil
+	}
+
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
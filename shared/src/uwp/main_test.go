@@ -0,0 +1,79 @@
+package uwp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseRoundTrip(t *testing.T) {
+	for _, s := range []string{"A788899-C", "X000000-0", "B9AA78J-G", "C56789A-?", "YA00000-0"} {
+		u, err := Parse(s)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", s, err)
+		}
+		if actual := u.String(); actual != s {
+			t.Errorf("Parse(%q).String() = %q", s, actual)
+		}
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	u, err := Parse("B9AA78J-G")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := UWP{Starport: 'B', Size: 9, Atmosphere: 10, Hydrographics: 10, Population: 7, Government: 8, Law: 18, TechLevel: 16}
+	if u != expected {
+		t.Errorf("expected %+v, got %+v", expected, u)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"A788899C",   // missing dash
+		"A788899-CC", // too long
+		"Z788899-C",  // unknown starport
+		"A7888I9-C",  // I is not an eHex digit
+		"A78B899-C",  // hydrographics past A
+		"A7887J9-C",  // government past F
+		"A788899-!",
+	} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("expected Parse(%q) to fail", s)
+		}
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for value := 0; value < len(Digits); value++ {
+		decoded, err := Decode(Encode(value)[0])
+		if err != nil || decoded != value {
+			t.Errorf("Decode(Encode(%d)) = %d, %v", value, decoded, err)
+		}
+	}
+
+	if actual := Encode(34); actual != "?" {
+		t.Errorf("expected ? for 34, got %s", actual)
+	}
+	if _, err := Decode('O'); err == nil {
+		t.Error("expected O not to decode")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var world struct {
+		UWP UWP `json:"UWP"`
+	}
+	if err := json.Unmarshal([]byte(`{"UWP":"A788899-C"}`), &world); err != nil {
+		t.Fatal(err)
+	}
+	if world.UWP.TechLevel != 12 {
+		t.Errorf("expected tech level 12, got %d", world.UWP.TechLevel)
+	}
+
+	if err := json.Unmarshal([]byte(`{"UWP":"nonsense"}`), &world); err == nil {
+		t.Error("expected an invalid UWP to fail")
+	}
+}